@@ -8,7 +8,16 @@
 package model
 
 type Room struct {
-	ID          int64 `sql:"primary_key"`
-	Name        string
-	Description string
+	ID            int64 `sql:"primary_key"`
+	Name          string
+	Description   string
+	Retention     *string
+	RetentionDays *int64
+	Kind          string
+	DirectKey     *string
+	Visibility    string
+	ParentID      *int64
+	SlowMode      int64
+	Announcement  bool
+	MemberDays    int64
 }
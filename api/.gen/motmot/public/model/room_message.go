@@ -8,9 +8,14 @@
 package model
 
 type RoomMessage struct {
-	ID       int64 `sql:"primary_key"`
-	UserID   int64
-	RoomID   int64
-	Contents string
-	Iat      int64
+	ID        int64 `sql:"primary_key"`
+	UserID    *int64
+	RoomID    int64
+	Contents  string
+	Iat       int64
+	EditedAt  *int64
+	DeletedAt *int64
+	ParentID  *int64
+	Kind      string
+	Payload   *string
 }
@@ -14,4 +14,5 @@ type UserAccount struct {
 	Hash        string
 	Email       string
 	Bio         *string
+	Privacy     string
 }
@@ -8,6 +8,11 @@
 package model
 
 type UserRoom struct {
-	UserID int64 `sql:"primary_key"`
-	RoomID int64 `sql:"primary_key"`
+	UserID     int64 `sql:"primary_key"`
+	RoomID     int64 `sql:"primary_key"`
+	Role       string
+	InviteID   *int64
+	JoinedAt   int64
+	Notify     string
+	MutedUntil *int64
 }
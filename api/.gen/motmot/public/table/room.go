@@ -17,9 +17,18 @@ type roomTable struct {
 	postgres.Table
 
 	// Columns
-	ID          postgres.ColumnInteger
-	Name        postgres.ColumnString
-	Description postgres.ColumnString
+	ID            postgres.ColumnInteger
+	Name          postgres.ColumnString
+	Description   postgres.ColumnString
+	Retention     postgres.ColumnString
+	RetentionDays postgres.ColumnInteger
+	Kind          postgres.ColumnString
+	DirectKey     postgres.ColumnString
+	Visibility    postgres.ColumnString
+	ParentID      postgres.ColumnInteger
+	SlowMode      postgres.ColumnInteger
+	Announcement  postgres.ColumnBool
+	MemberDays    postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -60,20 +69,38 @@ func newRoomTable(schemaName, tableName, alias string) *RoomTable {
 
 func newRoomTableImpl(schemaName, tableName, alias string) roomTable {
 	var (
-		IDColumn          = postgres.IntegerColumn("id")
-		NameColumn        = postgres.StringColumn("name")
-		DescriptionColumn = postgres.StringColumn("description")
-		allColumns        = postgres.ColumnList{IDColumn, NameColumn, DescriptionColumn}
-		mutableColumns    = postgres.ColumnList{NameColumn, DescriptionColumn}
+		IDColumn            = postgres.IntegerColumn("id")
+		NameColumn          = postgres.StringColumn("name")
+		DescriptionColumn   = postgres.StringColumn("description")
+		RetentionColumn     = postgres.StringColumn("retention")
+		RetentionDaysColumn = postgres.IntegerColumn("retention_days")
+		KindColumn          = postgres.StringColumn("kind")
+		DirectKeyColumn     = postgres.StringColumn("direct_key")
+		VisibilityColumn    = postgres.StringColumn("visibility")
+		ParentIDColumn      = postgres.IntegerColumn("parent_id")
+		SlowModeColumn      = postgres.IntegerColumn("slow_mode")
+		AnnouncementColumn  = postgres.BoolColumn("announcement")
+		MemberDaysColumn    = postgres.IntegerColumn("member_days")
+		allColumns          = postgres.ColumnList{IDColumn, NameColumn, DescriptionColumn, RetentionColumn, RetentionDaysColumn, KindColumn, DirectKeyColumn, VisibilityColumn, ParentIDColumn, SlowModeColumn, AnnouncementColumn, MemberDaysColumn}
+		mutableColumns      = postgres.ColumnList{NameColumn, DescriptionColumn, RetentionColumn, RetentionDaysColumn, KindColumn, DirectKeyColumn, VisibilityColumn, ParentIDColumn, SlowModeColumn, AnnouncementColumn, MemberDaysColumn}
 	)
 
 	return roomTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:          IDColumn,
-		Name:        NameColumn,
-		Description: DescriptionColumn,
+		ID:            IDColumn,
+		Name:          NameColumn,
+		Description:   DescriptionColumn,
+		Retention:     RetentionColumn,
+		RetentionDays: RetentionDaysColumn,
+		Kind:          KindColumn,
+		DirectKey:     DirectKeyColumn,
+		Visibility:    VisibilityColumn,
+		ParentID:      ParentIDColumn,
+		SlowMode:      SlowModeColumn,
+		Announcement:  AnnouncementColumn,
+		MemberDays:    MemberDaysColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
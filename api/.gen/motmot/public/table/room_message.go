@@ -17,11 +17,16 @@ type roomMessageTable struct {
 	postgres.Table
 
 	// Columns
-	ID       postgres.ColumnInteger
-	UserID   postgres.ColumnInteger
-	RoomID   postgres.ColumnInteger
-	Contents postgres.ColumnString
-	Iat      postgres.ColumnInteger
+	ID        postgres.ColumnInteger
+	UserID    postgres.ColumnInteger
+	RoomID    postgres.ColumnInteger
+	Contents  postgres.ColumnString
+	Iat       postgres.ColumnInteger
+	EditedAt  postgres.ColumnInteger
+	DeletedAt postgres.ColumnInteger
+	ParentID  postgres.ColumnInteger
+	Kind      postgres.ColumnString
+	Payload   postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -62,24 +67,34 @@ func newRoomMessageTable(schemaName, tableName, alias string) *RoomMessageTable
 
 func newRoomMessageTableImpl(schemaName, tableName, alias string) roomMessageTable {
 	var (
-		IDColumn       = postgres.IntegerColumn("id")
-		UserIDColumn   = postgres.IntegerColumn("user_id")
-		RoomIDColumn   = postgres.IntegerColumn("room_id")
-		ContentsColumn = postgres.StringColumn("contents")
-		IatColumn      = postgres.IntegerColumn("iat")
-		allColumns     = postgres.ColumnList{IDColumn, UserIDColumn, RoomIDColumn, ContentsColumn, IatColumn}
-		mutableColumns = postgres.ColumnList{UserIDColumn, RoomIDColumn, ContentsColumn, IatColumn}
+		IDColumn        = postgres.IntegerColumn("id")
+		UserIDColumn    = postgres.IntegerColumn("user_id")
+		RoomIDColumn    = postgres.IntegerColumn("room_id")
+		ContentsColumn  = postgres.StringColumn("contents")
+		IatColumn       = postgres.IntegerColumn("iat")
+		EditedAtColumn  = postgres.IntegerColumn("edited_at")
+		DeletedAtColumn = postgres.IntegerColumn("deleted_at")
+		ParentIDColumn  = postgres.IntegerColumn("parent_id")
+		KindColumn      = postgres.StringColumn("kind")
+		PayloadColumn   = postgres.StringColumn("payload")
+		allColumns      = postgres.ColumnList{IDColumn, UserIDColumn, RoomIDColumn, ContentsColumn, IatColumn, EditedAtColumn, DeletedAtColumn, ParentIDColumn, KindColumn, PayloadColumn}
+		mutableColumns  = postgres.ColumnList{UserIDColumn, RoomIDColumn, ContentsColumn, IatColumn, EditedAtColumn, DeletedAtColumn, ParentIDColumn, KindColumn, PayloadColumn}
 	)
 
 	return roomMessageTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:       IDColumn,
-		UserID:   UserIDColumn,
-		RoomID:   RoomIDColumn,
-		Contents: ContentsColumn,
-		Iat:      IatColumn,
+		ID:        IDColumn,
+		UserID:    UserIDColumn,
+		RoomID:    RoomIDColumn,
+		Contents:  ContentsColumn,
+		Iat:       IatColumn,
+		EditedAt:  EditedAtColumn,
+		DeletedAt: DeletedAtColumn,
+		ParentID:  ParentIDColumn,
+		Kind:      KindColumn,
+		Payload:   PayloadColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -10,8 +10,26 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	ExportJob = ExportJob.FromSchema(schema)
+	MessageAttachment = MessageAttachment.FromSchema(schema)
+	MessageBookmark = MessageBookmark.FromSchema(schema)
+	MessageMention = MessageMention.FromSchema(schema)
+	MessagePin = MessagePin.FromSchema(schema)
+	MessageReaction = MessageReaction.FromSchema(schema)
+	MessageReport = MessageReport.FromSchema(schema)
+	ModerationFlag = ModerationFlag.FromSchema(schema)
+	ModerationLog = ModerationLog.FromSchema(schema)
+	ModerationRule = ModerationRule.FromSchema(schema)
+	Notification = Notification.FromSchema(schema)
+	Poll = Poll.FromSchema(schema)
+	PollOption = PollOption.FromSchema(schema)
+	PollVote = PollVote.FromSchema(schema)
 	Room = Room.FromSchema(schema)
+	RoomInvite = RoomInvite.FromSchema(schema)
 	RoomMessage = RoomMessage.FromSchema(schema)
+	RoomMessageRevision = RoomMessageRevision.FromSchema(schema)
+	RoomSanction = RoomSanction.FromSchema(schema)
+	ScheduledMessage = ScheduledMessage.FromSchema(schema)
 	UserAccount = UserAccount.FromSchema(schema)
 	UserBlock = UserBlock.FromSchema(schema)
 	UserRoom = UserRoom.FromSchema(schema)
@@ -23,6 +23,7 @@ type userAccountTable struct {
 	Hash        postgres.ColumnString
 	Email       postgres.ColumnString
 	Bio         postgres.ColumnString
+	Privacy     postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -69,8 +70,9 @@ func newUserAccountTableImpl(schemaName, tableName, alias string) userAccountTab
 		HashColumn        = postgres.StringColumn("hash")
 		EmailColumn       = postgres.StringColumn("email")
 		BioColumn         = postgres.StringColumn("bio")
-		allColumns        = postgres.ColumnList{IDColumn, IdentifierColumn, DisplayNameColumn, HashColumn, EmailColumn, BioColumn}
-		mutableColumns    = postgres.ColumnList{IdentifierColumn, DisplayNameColumn, HashColumn, EmailColumn, BioColumn}
+		PrivacyColumn     = postgres.StringColumn("privacy")
+		allColumns        = postgres.ColumnList{IDColumn, IdentifierColumn, DisplayNameColumn, HashColumn, EmailColumn, BioColumn, PrivacyColumn}
+		mutableColumns    = postgres.ColumnList{IdentifierColumn, DisplayNameColumn, HashColumn, EmailColumn, BioColumn, PrivacyColumn}
 	)
 
 	return userAccountTable{
@@ -83,6 +85,7 @@ func newUserAccountTableImpl(schemaName, tableName, alias string) userAccountTab
 		Hash:        HashColumn,
 		Email:       EmailColumn,
 		Bio:         BioColumn,
+		Privacy:     PrivacyColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,8 +17,13 @@ type userRoomTable struct {
 	postgres.Table
 
 	// Columns
-	UserID postgres.ColumnInteger
-	RoomID postgres.ColumnInteger
+	UserID     postgres.ColumnInteger
+	RoomID     postgres.ColumnInteger
+	Role       postgres.ColumnString
+	InviteID   postgres.ColumnInteger
+	JoinedAt   postgres.ColumnInteger
+	Notify     postgres.ColumnString
+	MutedUntil postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -59,18 +64,28 @@ func newUserRoomTable(schemaName, tableName, alias string) *UserRoomTable {
 
 func newUserRoomTableImpl(schemaName, tableName, alias string) userRoomTable {
 	var (
-		UserIDColumn   = postgres.IntegerColumn("user_id")
-		RoomIDColumn   = postgres.IntegerColumn("room_id")
-		allColumns     = postgres.ColumnList{UserIDColumn, RoomIDColumn}
-		mutableColumns = postgres.ColumnList{}
+		UserIDColumn     = postgres.IntegerColumn("user_id")
+		RoomIDColumn     = postgres.IntegerColumn("room_id")
+		RoleColumn       = postgres.StringColumn("role")
+		InviteIDColumn   = postgres.IntegerColumn("invite_id")
+		JoinedAtColumn   = postgres.IntegerColumn("joined_at")
+		NotifyColumn     = postgres.StringColumn("notify")
+		MutedUntilColumn = postgres.IntegerColumn("muted_until")
+		allColumns       = postgres.ColumnList{UserIDColumn, RoomIDColumn, RoleColumn, InviteIDColumn, JoinedAtColumn, NotifyColumn, MutedUntilColumn}
+		mutableColumns   = postgres.ColumnList{RoleColumn, InviteIDColumn, JoinedAtColumn, NotifyColumn, MutedUntilColumn}
 	)
 
 	return userRoomTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UserID: UserIDColumn,
-		RoomID: RoomIDColumn,
+		UserID:     UserIDColumn,
+		RoomID:     RoomIDColumn,
+		Role:       RoleColumn,
+		InviteID:   InviteIDColumn,
+		JoinedAt:   JoinedAtColumn,
+		Notify:     NotifyColumn,
+		MutedUntil: MutedUntilColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -7,16 +7,154 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/gorilla/websocket"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
 	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/export"
 	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/group"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+	"github.com/tetrago/motmot/api/internal/retention"
+	"github.com/tetrago/motmot/api/internal/schedule"
 	"github.com/tetrago/motmot/api/internal/user"
 )
 
+// send performs a request as the user holding a token cookie, if any, with a
+// body encoded as JSON, if any.
+func send(router *gin.Engine, cookie *http.Cookie, method string, path string, body any) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if cookie != nil {
+		req.Header.Set("Cookie", fmt.Sprintf("token=%s", cookie.Value))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// register creates a new user and logs them in, returning their identifier and
+// token cookie.
+func register(t *testing.T, router *gin.Engine) (string, *http.Cookie) {
+	email, _ := user.MakeIdentifier()
+
+	w := send(router, nil, "POST", "/api/v1/user/register", user.RegisterRequest{
+		DisplayName: "Name",
+		Email:       email,
+		Password:    "password",
+	})
+	assert.Equal(t, 200, w.Code)
+
+	var response user.RegisterResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	w = send(router, nil, "POST", "/api/v1/auth/login", auth.LoginRequest{
+		Email:    email,
+		Password: "password",
+	})
+	assert.Equal(t, 200, w.Code)
+
+	cookie := w.Result().Cookies()[0]
+	assert.Equal(t, cookie.Name, "token")
+
+	return response.Identifier, cookie
+}
+
+// createGroup creates a study group owned by the user with a visibility.
+func createGroup(t *testing.T, router *gin.Engine, cookie *http.Cookie, visibility string) int64 {
+	w := send(router, cookie, "POST", "/api/v1/group/create", group.CreateRequest{
+		Name:       "Group",
+		Visibility: visibility,
+	})
+	assert.Equal(t, 200, w.Code)
+
+	id, _ := strconv.ParseInt(w.Body.String(), 10, 64)
+	return id
+}
+
+// upload sends a file to a group as the user holding a token cookie.
+func upload(router *gin.Engine, cookie *http.Cookie, groupId int64, name string, contents []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("group_id", strconv.FormatInt(groupId, 10))
+
+	file, _ := form.CreateFormFile("file", name)
+	file.Write(contents)
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/group/attachment", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Cookie", fmt.Sprintf("token=%s", cookie.Value))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// dial opens a socket on a group as the user holding a token cookie.
+func dial(t *testing.T, server *httptest.Server, cookie *http.Cookie, groupId int64) *websocket.Conn {
+	url := fmt.Sprintf("ws%s/api/v1/ws/%d", strings.TrimPrefix(server.URL, "http"), groupId)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Cookie": {fmt.Sprintf("token=%s", cookie.Value)}})
+	assert.Nil(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// account gets the user with an identifier.
+func account(t *testing.T, ident string) model.UserAccount {
+	var dest model.UserAccount
+	err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(ident))).Query(globals.Database, &dest)
+	assert.Nil(t, err)
+
+	return dest
+}
+
+// promote makes a member of a group one of its moderators. No route changes
+// roles, so the membership is updated directly.
+func promote(t *testing.T, ident string, groupId int64) {
+	stmt := UserRoom.UPDATE(UserRoom.Role).SET(String(member.RoleModerator)).WHERE(
+		UserRoom.UserID.EQ(Int64(account(t, ident).ID)).AND(UserRoom.RoomID.EQ(Int64(groupId))),
+	)
+
+	_, err := stmt.Exec(globals.Database)
+	assert.Nil(t, err)
+}
+
+// post sends a message to a group as a user, returning its ID. Messages are
+// otherwise only sent over a socket, which does not echo them to their author.
+func post(t *testing.T, ident string, groupId int64, contents string, parent *int64) int64 {
+	msg, err := chat.Post(account(t, ident), groupId, chat.Draft{Contents: contents, ParentID: parent}, nil)
+	assert.Nil(t, err)
+
+	return msg.ID
+}
+
 func TestRegistrationAndLogin(t *testing.T) {
 	router := setupRouter()
 	globals.Database = setupDatabase()
@@ -190,3 +328,2158 @@ func TestJoin(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, 200, w.Code)
 }
+
+func TestMessageRevisions(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	_, other := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, other, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	id := post(t, ownerIdent, groupId, "first", nil)
+
+	w = send(router, other, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: id, Contents: "changed"})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, other, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: id})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: id, Contents: "second"})
+	assert.Equal(t, 200, w.Code)
+
+	path := fmt.Sprintf("/api/v1/group/revisions/%d", id)
+
+	w = send(router, nil, "GET", path, nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, other, "GET", path, nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "GET", path, nil)
+	assert.Equal(t, 200, w.Code)
+
+	var revisions []group.RevisionsResponseItem
+	json.Unmarshal(w.Body.Bytes(), &revisions)
+
+	if assert.Len(t, revisions, 1) {
+		assert.Equal(t, "first", revisions[0].Contents)
+		assert.Equal(t, ownerIdent, revisions[0].Identifier)
+	}
+
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: id})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, owner, "GET", path, nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: id, Contents: "third"})
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestHistoryCursors(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ident, cookie := register(t, router)
+	groupId := createGroup(t, router, cookie, member.VisibilityPublic)
+
+	// Messages sent within the same second are ordered by ID
+	var sent []int64
+	for i := 0; i < 7; i++ {
+		sent = append(sent, post(t, ident, groupId, fmt.Sprintf("message %d", i), nil))
+	}
+
+	history := func(query string) group.HistoryResponse {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/history/%d?%s", groupId, query), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.HistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	ids := func(response group.HistoryResponse) []int64 {
+		var dest []int64
+		for _, x := range response.Messages {
+			dest = append(dest, x.ID)
+		}
+
+		return dest
+	}
+
+	all := ids(history("limit=100"))
+
+	// Paging towards older messages visits every message once, newest first
+	var older []int64
+	page := history("limit=3")
+	assert.Nil(t, page.Prev)
+
+	var last group.HistoryResponse
+	for {
+		older = append(older, ids(page)...)
+		last = page
+
+		if page.Next == nil {
+			break
+		}
+
+		page = history("limit=3&before=" + *page.Next)
+	}
+
+	assert.Equal(t, all, older)
+
+	// Paging back towards newer messages from the oldest page visits the rest
+	var newer []int64
+	page = history("limit=3&after=" + *last.Prev)
+	for {
+		newer = append(ids(page), newer...)
+		if page.Prev == nil {
+			break
+		}
+
+		page = history("limit=3&after=" + *page.Prev)
+	}
+
+	assert.Equal(t, all[:len(all)-len(ids(last))], newer)
+
+	// A single message is the target alone, with cursors to either side of it
+	page = history(fmt.Sprintf("limit=1&around=%d", sent[3]))
+	assert.Equal(t, []int64{sent[3]}, ids(page))
+	assert.NotNil(t, page.Next)
+	assert.NotNil(t, page.Prev)
+
+	page = history("limit=1&before=" + *page.Next)
+	assert.Equal(t, []int64{sent[2]}, ids(page))
+
+	page = history(fmt.Sprintf("limit=1&around=%d", sent[3]))
+	page = history("limit=1&after=" + *page.Prev)
+	assert.Equal(t, []int64{sent[4]}, ids(page))
+
+	page = history(fmt.Sprintf("limit=3&around=%d", sent[3]))
+	assert.Equal(t, []int64{sent[4], sent[3], sent[2]}, ids(page))
+}
+
+func TestBan(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, owner := register(t, router)
+	targetIdent, target := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, target, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	conn := dial(t, server, target, groupId)
+	defer conn.Close()
+
+	w = send(router, target, "POST", "/api/v1/group/ban", group.SanctionRequest{GroupID: groupId, Identifier: targetIdent, Reason: "Spam"})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/ban", group.SanctionRequest{GroupID: groupId, Identifier: targetIdent, Reason: "Spam"})
+	assert.Equal(t, 200, w.Code)
+
+	// Events already queued may arrive before the socket is closed
+	var err error
+	for err == nil {
+		_, _, err = conn.ReadMessage()
+	}
+
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+
+	w = send(router, target, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, target, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 403, w.Code)
+
+	reconnect := dial(t, server, target, groupId)
+	defer reconnect.Close()
+
+	var frame struct {
+		Type  string `json:"type"`
+		Error string `json:"error"`
+	}
+
+	assert.Nil(t, reconnect.ReadJSON(&frame))
+	assert.Equal(t, "error", frame.Type)
+	assert.Equal(t, chat.ErrNotMember.Error(), frame.Error)
+}
+
+func TestPrivateGroupAccess(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPrivate)
+	id := post(t, ownerIdent, groupId, "secret", nil)
+
+	w := send(router, outsider, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, nil, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 403, w.Code)
+
+	// Messages that cannot be seen are treated as though they do not exist
+	w = send(router, outsider, "POST", "/api/v1/group/react", group.ReactRequest{MessageID: id, Emoji: "👍"})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/unreact", group.ReactRequest{MessageID: id, Emoji: "👍"})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: id})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/report", group.ReportRequest{MessageID: id, Reason: "Spam"})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/invite", group.InviteRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	var invite group.InviteResponseItem
+	json.Unmarshal(w.Body.Bytes(), &invite)
+
+	w = send(router, outsider, "POST", "/api/v1/group/invite/accept/"+invite.Code, nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, outsider, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/react", group.ReactRequest{MessageID: id, Emoji: "👍"})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/unreact", group.ReactRequest{MessageID: id, Emoji: "👍"})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, outsider, "POST", "/api/v1/group/report", group.ReportRequest{MessageID: id, Reason: "Spam"})
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestBlockedAuthors(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	authorIdent, author := register(t, router)
+	_, viewer := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, author, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	kept := post(t, ownerIdent, groupId, "photosynthesis notes", nil)
+	hidden := post(t, authorIdent, groupId, "photosynthesis answers", nil)
+
+	w = send(router, viewer, "POST", "/api/v1/user/block", user.BlockRequest{Identifier: authorIdent})
+	assert.Equal(t, 200, w.Code)
+
+	history := func(cookie *http.Cookie) []int64 {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.HistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		var dest []int64
+		for _, x := range response.Messages {
+			dest = append(dest, x.ID)
+		}
+
+		return dest
+	}
+
+	search := func(cookie *http.Cookie) []int64 {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/search/%d?query=photosynthesis&limit=20", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.SearchResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		var dest []int64
+		for _, x := range response.Results {
+			dest = append(dest, x.ID)
+		}
+
+		return dest
+	}
+
+	assert.Contains(t, history(viewer), kept)
+	assert.NotContains(t, history(viewer), hidden)
+	assert.Contains(t, history(owner), hidden)
+
+	assert.Equal(t, []int64{kept}, search(viewer))
+	assert.ElementsMatch(t, []int64{kept, hidden}, search(owner))
+
+	w = send(router, viewer, "POST", "/api/v1/user/unblock", user.BlockRequest{Identifier: authorIdent})
+	assert.Equal(t, 200, w.Code)
+
+	assert.Contains(t, history(viewer), hidden)
+	assert.ElementsMatch(t, []int64{kept, hidden}, search(viewer))
+}
+
+func TestInvites(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	_, owner := register(t, router)
+	groupId := createGroup(t, router, owner, member.VisibilityPrivate)
+
+	invite := func(expiresAt *int64, maxUses *int64) group.InviteResponseItem {
+		w := send(router, owner, "POST", "/api/v1/group/invite", group.InviteRequest{GroupID: groupId, ExpiresAt: expiresAt, MaxUses: maxUses})
+		assert.Equal(t, 200, w.Code)
+
+		var response group.InviteResponseItem
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	accept := func(cookie *http.Cookie, code string) int {
+		return send(router, cookie, "POST", "/api/v1/group/invite/accept/"+code, nil).Code
+	}
+
+	past := time.Now().Unix() - 60
+	w := send(router, owner, "POST", "/api/v1/group/invite", group.InviteRequest{GroupID: groupId, ExpiresAt: &past})
+	assert.Equal(t, 400, w.Code)
+
+	// Invites may be used up
+	single := invite(nil, lo.ToPtr(int64(1)))
+
+	_, first := register(t, router)
+	_, second := register(t, router)
+
+	assert.Equal(t, 200, accept(first, single.Code))
+	assert.Equal(t, 200, accept(first, single.Code))
+	assert.Equal(t, 410, accept(second, single.Code))
+
+	// Invites may expire
+	expiring := invite(lo.ToPtr(time.Now().Unix()+1), nil)
+	time.Sleep(2 * time.Second)
+
+	assert.Equal(t, 410, accept(second, expiring.Code))
+
+	// Revoking an invite may remove those who joined through it
+	revoked := invite(nil, nil)
+
+	joinedIdent, joined := register(t, router)
+	assert.Equal(t, 200, accept(joined, revoked.Code))
+
+	w = send(router, first, "POST", "/api/v1/group/invite/revoke", group.RevokeInviteRequest{ID: revoked.ID, RemoveMembers: true})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/invite/revoke", group.RevokeInviteRequest{ID: revoked.ID, RemoveMembers: true})
+	assert.Equal(t, 200, w.Code)
+
+	var response group.RevokeInviteResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Removed)
+
+	w = send(router, joined, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, first, "GET", fmt.Sprintf("/api/v1/group/history/%d", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	assert.Equal(t, 410, accept(second, revoked.Code))
+
+	w = send(router, owner, "GET", fmt.Sprintf("/api/v1/group/log/%d?limit=10", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), joinedIdent)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	thread := post(t, memberIdent, groupId, "question", nil)
+
+	notifications := func() []inbox.Item {
+		w := send(router, memberCookie, "GET", "/api/v1/user/notifications?limit=50", nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response user.NotificationsResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.Notifications
+	}
+
+	notified := func(id int64) bool {
+		return lo.ContainsBy(notifications(), func(x inbox.Item) bool { return x.MessageID != nil && *x.MessageID == id })
+	}
+
+	prefer := func(level string, mutedUntil *int64) int {
+		return send(router, memberCookie, "POST", "/api/v1/user/notifications/group", user.GroupNotificationsRequest{GroupID: groupId, Level: level, MutedUntil: mutedUntil}).Code
+	}
+
+	mention := func() int64 { return post(t, ownerIdent, groupId, "@"+memberIdent+" hello", nil) }
+	reply := func() int64 { return post(t, ownerIdent, groupId, "answer", &thread) }
+
+	assert.True(t, notified(mention()))
+	assert.True(t, notified(reply()))
+
+	assert.Equal(t, 400, prefer("sometimes", nil))
+	assert.Equal(t, 400, prefer(member.NotifyAll, lo.ToPtr(int64(-1))))
+
+	_, outsider := register(t, router)
+	w = send(router, outsider, "POST", "/api/v1/user/notifications/group", user.GroupNotificationsRequest{GroupID: groupId, Level: member.NotifyAll})
+	assert.Equal(t, 400, w.Code)
+
+	assert.Equal(t, 200, prefer(member.NotifyMentions, nil))
+	assert.True(t, notified(mention()))
+	assert.False(t, notified(reply()))
+
+	assert.Equal(t, 200, prefer(member.NotifyNothing, nil))
+	assert.False(t, notified(mention()))
+	assert.False(t, notified(reply()))
+
+	assert.Equal(t, 200, prefer(member.NotifyAll, lo.ToPtr(time.Now().Unix()+3600)))
+	assert.False(t, notified(mention()))
+	assert.False(t, notified(reply()))
+
+	assert.Equal(t, 200, prefer(member.NotifyAll, lo.ToPtr(time.Now().Unix()-3600)))
+	assert.True(t, notified(mention()))
+	assert.True(t, notified(reply()))
+
+	// Moderator actions are never silenced
+	assert.Equal(t, 200, prefer(member.NotifyNothing, nil))
+
+	w = send(router, owner, "POST", "/api/v1/group/mute", group.SanctionRequest{GroupID: groupId, Identifier: memberIdent, Reason: "Spam", Duration: 60})
+	assert.Equal(t, 200, w.Code)
+
+	assert.True(t, lo.ContainsBy(notifications(), func(x inbox.Item) bool {
+		return x.Kind == inbox.KindModeration && x.GroupID != nil && *x.GroupID == groupId
+	}))
+}
+
+func TestThreads(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ident, cookie := register(t, router)
+	groupId := createGroup(t, router, cookie, member.VisibilityPublic)
+
+	parent := post(t, ident, groupId, "question", nil)
+
+	var replies []int64
+	for i := 0; i < 3; i++ {
+		replies = append(replies, post(t, ident, groupId, fmt.Sprintf("answer %d", i), &parent))
+	}
+
+	// Threads are a single level deep
+	_, err := chat.Post(account(t, ident), groupId, chat.Draft{Contents: "nested", ParentID: &replies[0]}, nil)
+	assert.Equal(t, chat.ErrParent, err)
+
+	// Replies are counted on their parent rather than listed in history
+	w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=10", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var history group.HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+
+	if assert.Len(t, history.Messages, 1) {
+		assert.Equal(t, parent, history.Messages[0].ID)
+		assert.Equal(t, int64(3), history.Messages[0].Replies)
+	}
+
+	thread := func(query string) group.ThreadResponse {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/thread/%d?%s", parent, query), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.ThreadResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	ids := func(response group.ThreadResponse) []int64 {
+		return lo.Map(response.Messages, func(x group.HistoryResponseItem, _ int) int64 { return x.ID })
+	}
+
+	page := thread("limit=2")
+	assert.Equal(t, replies[:2], ids(page))
+
+	if assert.NotNil(t, page.Next) {
+		page = thread("limit=2&after=" + *page.Next)
+		assert.Equal(t, replies[2:], ids(page))
+		assert.Nil(t, page.Next)
+	}
+
+	w = send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/thread/%d?limit=2&after=bad", parent), nil)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReactions(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	id := post(t, ownerIdent, groupId, "question", nil)
+
+	conn := dial(t, server, owner, groupId)
+	defer conn.Close()
+
+	react := func(cookie *http.Cookie, emoji string) int {
+		return send(router, cookie, "POST", "/api/v1/group/react", group.ReactRequest{MessageID: id, Emoji: emoji}).Code
+	}
+
+	reactions := func(cookie *http.Cookie) []chat.Reaction {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=10", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.HistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		item, _ := lo.Find(response.Messages, func(x group.HistoryResponseItem) bool { return x.ID == id })
+		return item.Reactions
+	}
+
+	assert.Equal(t, 400, react(memberCookie, "not emoji"))
+	assert.Equal(t, 400, react(memberCookie, ""))
+
+	// Reacting twice with the same emoji counts once
+	assert.Equal(t, 200, react(memberCookie, "👍"))
+	assert.Equal(t, 200, react(memberCookie, "👍"))
+	assert.Equal(t, 200, react(owner, "👍"))
+	assert.Equal(t, 200, react(memberCookie, "🎉"))
+
+	var frame chat.ReactionEvent
+	for frame.Type != chat.EventReactionAdded {
+		assert.Nil(t, conn.ReadJSON(&frame))
+	}
+
+	assert.Equal(t, id, frame.ID)
+	assert.Equal(t, memberIdent, frame.Identifier)
+	assert.Equal(t, "👍", frame.Emoji)
+
+	// Reactions made within the same second have no set order
+	assert.ElementsMatch(t, []chat.Reaction{{Emoji: "👍", Count: 2, Reacted: true}, {Emoji: "🎉", Count: 1, Reacted: true}}, reactions(memberCookie))
+	assert.ElementsMatch(t, []chat.Reaction{{Emoji: "👍", Count: 2, Reacted: true}, {Emoji: "🎉", Count: 1, Reacted: false}}, reactions(owner))
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/unreact", group.ReactRequest{MessageID: id, Emoji: "👍"})
+	assert.Equal(t, 200, w.Code)
+
+	assert.ElementsMatch(t, []chat.Reaction{{Emoji: "👍", Count: 1, Reacted: false}, {Emoji: "🎉", Count: 1, Reacted: true}}, reactions(memberCookie))
+}
+
+func TestMentions(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	firstIdent, first := register(t, router)
+	secondIdent, second := register(t, router)
+	outsiderIdent, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	for _, cookie := range []*http.Cookie{first, second} {
+		w := send(router, cookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+		assert.Equal(t, 200, w.Code)
+	}
+
+	mentions := func(cookie *http.Cookie) []int64 {
+		w := send(router, cookie, "GET", "/api/v1/user/mentions?limit=20", nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response []user.MentionsResponseItem
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return lo.Map(response, func(x user.MentionsResponseItem, _ int) int64 { return x.ID })
+	}
+
+	// Only moderators may mention everyone
+	ignored := post(t, firstIdent, groupId, "@everyone look", nil)
+	everyone := post(t, ownerIdent, groupId, "@everyone look", nil)
+
+	assert.NotContains(t, mentions(second), ignored)
+	assert.Contains(t, mentions(first), everyone)
+	assert.Contains(t, mentions(second), everyone)
+	assert.NotContains(t, mentions(owner), everyone)
+
+	direct := post(t, secondIdent, groupId, "thanks @"+firstIdent+" and @"+secondIdent, nil)
+	assert.Contains(t, mentions(first), direct)
+	assert.NotContains(t, mentions(second), direct)
+
+	moderators := post(t, firstIdent, groupId, "help @moderators", nil)
+	assert.Contains(t, mentions(owner), moderators)
+	assert.NotContains(t, mentions(second), moderators)
+
+	// Members who have blocked the author are not mentioned, nor are outsiders
+	w := send(router, first, "POST", "/api/v1/user/block", user.BlockRequest{Identifier: secondIdent})
+	assert.Equal(t, 200, w.Code)
+
+	blocked := post(t, secondIdent, groupId, "@"+firstIdent+" @"+outsiderIdent+" again", nil)
+	assert.NotContains(t, mentions(first), blocked)
+	assert.Empty(t, mentions(outsider))
+}
+
+func TestPins(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	id := post(t, memberIdent, groupId, "notes", nil)
+
+	pin := func(cookie *http.Cookie, id int64) int {
+		return send(router, cookie, "POST", "/api/v1/group/pin", group.PinRequest{MessageID: id}).Code
+	}
+
+	pins := func() []group.PinsResponseItem {
+		w := send(router, memberCookie, "GET", fmt.Sprintf("/api/v1/group/pins/%d", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response []group.PinsResponseItem
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	assert.Equal(t, 403, pin(memberCookie, id))
+	assert.Equal(t, 200, pin(owner, id))
+	assert.Equal(t, 200, pin(owner, id))
+
+	if p := pins(); assert.Len(t, p, 1) {
+		assert.Equal(t, id, p[0].ID)
+		assert.Equal(t, memberIdent, p[0].Identifier)
+		assert.Equal(t, ownerIdent, p[0].PinnedBy)
+	}
+
+	// Pinning is announced once with a system message
+	w = send(router, memberCookie, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=20", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var history group.HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+
+	announced := lo.Filter(history.Messages, func(x group.HistoryResponseItem, _ int) bool {
+		return x.System != nil && x.System.Event == chat.SystemPinned
+	})
+
+	if assert.Len(t, announced, 1) {
+		assert.Equal(t, id, *announced[0].System.MessageID)
+		assert.Equal(t, ownerIdent, announced[0].System.Moderator)
+	}
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/unpin", group.PinRequest{MessageID: id})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/unpin", group.PinRequest{MessageID: id})
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, pins())
+
+	// Groups hold a limited number of pins
+	for i := 0; i < chat.MaxPins; i++ {
+		assert.Equal(t, 200, pin(owner, post(t, ownerIdent, groupId, fmt.Sprintf("pin %d", i), nil)))
+	}
+
+	assert.Equal(t, 400, pin(owner, id))
+
+	// Deleting a pinned message unpins it
+	last := pins()[0].ID
+
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: last})
+	assert.Equal(t, 200, w.Code)
+
+	assert.Len(t, pins(), chat.MaxPins-1)
+	assert.Equal(t, 200, pin(owner, id))
+}
+
+func TestAttachments(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	globals.Opts.AttachmentFolderPath = t.TempDir()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPrivate)
+
+	w := send(router, owner, "POST", "/api/v1/group/invite", group.InviteRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	var invite group.InviteResponseItem
+	json.Unmarshal(w.Body.Bytes(), &invite)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/invite/accept/"+invite.Code, nil)
+	assert.Equal(t, 200, w.Code)
+
+	contents := []byte("photosynthesis converts light into chemical energy")
+
+	assert.Equal(t, 403, upload(router, outsider, groupId, "notes.txt", contents).Code)
+	assert.Equal(t, 415, upload(router, memberCookie, groupId, "page.txt", []byte("<html><body>script</body></html>")).Code)
+
+	w = upload(router, memberCookie, groupId, "../notes.txt", contents)
+	assert.Equal(t, 200, w.Code)
+
+	var attachment chat.Attachment
+	json.Unmarshal(w.Body.Bytes(), &attachment)
+
+	assert.Equal(t, "notes.txt", attachment.Name)
+	assert.Equal(t, "text/plain", attachment.ContentType)
+	assert.Equal(t, int64(len(contents)), attachment.Size)
+
+	download := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		return send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/attachment/%d", attachment.ID), nil)
+	}
+
+	// Pending uploads are only visible to the uploader
+	assert.Equal(t, 200, download(memberCookie).Code)
+	assert.Equal(t, 400, download(owner).Code)
+
+	// Uploads may only be attached by their uploader
+	_, err := chat.Post(account(t, ownerIdent), groupId, chat.Draft{Attachments: []int64{attachment.ID}}, nil)
+	assert.Equal(t, chat.ErrAttachment, err)
+
+	msg, err := chat.Post(account(t, memberIdent), groupId, chat.Draft{Attachments: []int64{attachment.ID}}, nil)
+	assert.Nil(t, err)
+
+	w = send(router, owner, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=10", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var history group.HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+
+	item, _ := lo.Find(history.Messages, func(x group.HistoryResponseItem) bool { return x.ID == msg.ID })
+	assert.Equal(t, []chat.Attachment{attachment}, item.Attachments)
+
+	w = download(owner)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, contents, w.Body.Bytes())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	assert.Equal(t, 401, download(nil).Code)
+	assert.Equal(t, 403, download(outsider).Code)
+
+	// Attachments leave with their message
+	w = send(router, memberCookie, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: msg.ID})
+	assert.Equal(t, 200, w.Code)
+
+	assert.Equal(t, 400, download(memberCookie).Code)
+}
+
+func TestModerationRules(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+	otherId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	filter := func(cookie *http.Cookie, word string, action string) *httptest.ResponseRecorder {
+		return send(router, cookie, "POST", "/api/v1/group/filter", group.FilterRequest{GroupID: groupId, Word: word, Action: action})
+	}
+
+	assert.Equal(t, 403, filter(memberCookie, "darn", moderation.ActionMask).Code)
+	assert.Equal(t, 400, filter(owner, "darn", "ignore").Code)
+	assert.Equal(t, 400, filter(owner, " ", moderation.ActionMask).Code)
+
+	assert.Equal(t, 200, filter(owner, "darn", moderation.ActionMask).Code)
+	assert.Equal(t, 200, filter(owner, "cheat", moderation.ActionFlag).Code)
+
+	w = filter(owner, "spoiler", moderation.ActionReject)
+	assert.Equal(t, 200, w.Code)
+
+	var reject group.FiltersResponseItem
+	json.Unmarshal(w.Body.Bytes(), &reject)
+
+	w = send(router, owner, "GET", fmt.Sprintf("/api/v1/group/filters/%d", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var filters []group.FiltersResponseItem
+	json.Unmarshal(w.Body.Bytes(), &filters)
+	assert.Equal(t, []string{"cheat", "darn", "spoiler"}, lo.Map(filters, func(x group.FiltersResponseItem, _ int) string { return x.Word }))
+
+	author := account(t, memberIdent)
+
+	_, err := chat.Post(author, groupId, chat.Draft{Contents: "big SPOILER ahead"}, nil)
+	assert.Equal(t, moderation.ErrRejected, err)
+
+	// Words must match whole
+	msg, err := chat.Post(author, groupId, chat.Draft{Contents: "spoilers are fine, but Darn it"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "spoilers are fine, but **** it", msg.Contents)
+
+	// Rules apply only to their own group
+	_, err = chat.Post(account(t, ownerIdent), otherId, chat.Draft{Contents: "big spoiler ahead"}, nil)
+	assert.Nil(t, err)
+
+	// Flagged messages are posted and reported to moderators
+	flagged, err := chat.Post(author, groupId, chat.Draft{Contents: "how to cheat"}, nil)
+	assert.Nil(t, err)
+
+	w = send(router, owner, "GET", fmt.Sprintf("/api/v1/group/reports?group_id=%d&limit=20", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var reports []group.ReportsResponseItem
+	json.Unmarshal(w.Body.Bytes(), &reports)
+
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, flagged.ID, reports[0].MessageID)
+		assert.Equal(t, "", reports[0].Reporter)
+		assert.Contains(t, reports[0].Reason, "cheat")
+	}
+
+	var flags []model.ModerationFlag
+	err = SELECT(ModerationFlag.AllColumns).FROM(ModerationFlag).WHERE(ModerationFlag.MessageID.EQ(Int64(flagged.ID))).Query(globals.Database, &flags)
+	assert.Nil(t, err)
+
+	if assert.Len(t, flags, 1) {
+		assert.Equal(t, moderation.FieldMessage, flags[0].Field)
+		assert.Equal(t, "cheat", flags[0].Words)
+	}
+
+	w = send(router, owner, "POST", "/api/v1/group/unfilter", group.UnfilterRequest{GroupID: groupId, ID: reject.ID})
+	assert.Equal(t, 200, w.Code)
+
+	_, err = chat.Post(author, groupId, chat.Draft{Contents: "big spoiler ahead"}, nil)
+	assert.Nil(t, err)
+}
+
+func TestReports(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	modIdent, mod := register(t, router)
+	authorIdent, author := register(t, router)
+	_, reporter := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	for _, cookie := range []*http.Cookie{mod, author, reporter} {
+		w := send(router, cookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+		assert.Equal(t, 200, w.Code)
+	}
+
+	promote(t, modIdent, groupId)
+
+	report := func(cookie *http.Cookie, id int64, reason string) int {
+		return send(router, cookie, "POST", "/api/v1/group/report", group.ReportRequest{MessageID: id, Reason: reason}).Code
+	}
+
+	reports := func(cookie *http.Cookie) []group.ReportsResponseItem {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/reports?group_id=%d&limit=20", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response []group.ReportsResponseItem
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	resolve := func(cookie *http.Cookie, id int64, action string) int {
+		return send(router, cookie, "POST", "/api/v1/group/resolve", group.ResolveRequest{ReportID: id, Action: action, Reason: "Rules"}).Code
+	}
+
+	id := post(t, authorIdent, groupId, "spam", nil)
+
+	assert.Equal(t, 403, report(author, id, "Spam"))
+	assert.Equal(t, 400, report(reporter, id, " "))
+	assert.Equal(t, 200, report(reporter, id, "Spam"))
+	assert.Equal(t, 409, report(reporter, id, "Spam"))
+	assert.Equal(t, 200, report(owner, id, "Spam"))
+
+	assert.Empty(t, reports(reporter))
+
+	queue := reports(mod)
+	if assert.Len(t, queue, 2) {
+		assert.Equal(t, id, queue[0].MessageID)
+		assert.Equal(t, authorIdent, queue[0].Identifier)
+	}
+
+	assert.Equal(t, 403, resolve(reporter, queue[0].ID, chat.ResolveDismiss))
+	assert.Equal(t, 400, resolve(mod, queue[0].ID, "ignore"))
+
+	// Resolving a report closes every report on the message
+	assert.Equal(t, 200, resolve(mod, queue[0].ID, chat.ResolveDelete))
+	assert.Empty(t, reports(mod))
+	assert.Equal(t, 400, resolve(mod, queue[1].ID, chat.ResolveDismiss))
+
+	w := send(router, author, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: id, Contents: "changed"})
+	assert.Equal(t, 400, w.Code)
+
+	// Authors are told of warnings
+	id = post(t, authorIdent, groupId, "more spam", nil)
+	assert.Equal(t, 200, report(reporter, id, "Spam"))
+	assert.Equal(t, 200, resolve(mod, reports(mod)[0].ID, chat.ResolveWarn))
+
+	w = send(router, author, "GET", "/api/v1/user/notifications?limit=50", nil)
+	assert.Equal(t, 200, w.Code)
+
+	var notifications user.NotificationsResponse
+	json.Unmarshal(w.Body.Bytes(), &notifications)
+
+	assert.True(t, lo.ContainsBy(notifications.Notifications, func(x inbox.Item) bool {
+		return x.Kind == inbox.KindModeration && x.GroupID != nil && *x.GroupID == groupId
+	}))
+
+	// Moderators may only sanction those below them
+	own := post(t, modIdent, groupId, "mine", nil)
+	owners := post(t, ownerIdent, groupId, "theirs", nil)
+
+	assert.Equal(t, 200, report(reporter, own, "Spam"))
+	assert.Equal(t, 200, report(reporter, owners, "Spam"))
+
+	queue = reports(owner)
+	assert.Len(t, queue, 2)
+
+	for _, x := range queue {
+		assert.Equal(t, 403, resolve(mod, x.ID, chat.ResolveMute))
+		assert.Equal(t, 403, resolve(mod, x.ID, chat.ResolveBan))
+	}
+
+	modReport, _ := lo.Find(queue, func(x group.ReportsResponseItem) bool { return x.MessageID == own })
+	assert.Equal(t, 200, resolve(owner, modReport.ID, chat.ResolveMute))
+
+	_, err := chat.Post(account(t, modIdent), groupId, chat.Draft{Contents: "muted"}, nil)
+	assert.Equal(t, chat.ErrMuted, err)
+}
+
+func TestSearch(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	strong := post(t, ownerIdent, groupId, "Photosynthesis: how photosynthesis uses light", nil)
+	weak := post(t, memberIdent, groupId, "notes on photosynthesis & the calvin cycle", nil)
+	post(t, ownerIdent, groupId, "unrelated cellular respiration", nil)
+
+	search := func(query string) group.SearchResponse {
+		w := send(router, nil, "GET", fmt.Sprintf("/api/v1/group/search/%d?%s", groupId, query), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.SearchResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	ids := func(response group.SearchResponse) []int64 {
+		return lo.Map(response.Results, func(x group.SearchResponseItem, _ int) int64 { return x.ID })
+	}
+
+	ranked := search("query=photosynthesis&limit=20")
+	assert.Equal(t, []int64{strong, weak}, ids(ranked))
+
+	// Snippets escape the contents around each match
+	if assert.Len(t, ranked.Results, 2) {
+		assert.Contains(t, ranked.Results[1].Snippet, "<mark>photosynthesis</mark>")
+		assert.Contains(t, ranked.Results[1].Snippet, "&amp;")
+	}
+
+	assert.Equal(t, []int64{weak, strong}, ids(search("query=photosynthesis&sort=recent&limit=20")))
+	assert.Equal(t, []int64{weak}, ids(search("query=photosynthesis&author="+memberIdent+"&limit=20")))
+	assert.Equal(t, []int64{strong}, ids(search("query=photosynthesis+-calvin&limit=20")))
+	assert.Equal(t, []int64{weak}, ids(search("query=%22calvin+cycle%22&limit=20")))
+	assert.Empty(t, ids(search(fmt.Sprintf("query=photosynthesis&from=%d&limit=20", time.Now().Unix()+60))))
+
+	// Paging visits every result once, in rank order
+	var paged []int64
+	page := search("query=photosynthesis&limit=1")
+	for {
+		paged = append(paged, ids(page)...)
+		if page.Next == nil {
+			break
+		}
+
+		page = search("query=photosynthesis&limit=1&cursor=" + *page.Next)
+	}
+
+	assert.Equal(t, []int64{strong, weak}, paged)
+
+	for _, query := range []string{"query=photosynthesis&limit=0", "query=photosynthesis&limit=21", "query=photosynthesis&sort=best&limit=20", "limit=20"} {
+		w := send(router, nil, "GET", fmt.Sprintf("/api/v1/group/search/%d?%s", groupId, query), nil)
+		assert.Equal(t, 400, w.Code)
+	}
+}
+
+func TestExport(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	globals.Opts.ExportFolderPath = t.TempDir()
+	go export.Worker()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	first := post(t, ownerIdent, groupId, "first, with a comma", nil)
+	second := post(t, memberIdent, groupId, "<script>alert(1)</script>", &first)
+	deleted := post(t, ownerIdent, groupId, "deleted", nil)
+
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: deleted})
+	assert.Equal(t, 200, w.Code)
+
+	path := func(format string) string {
+		return fmt.Sprintf("/api/v1/group/export/%d?format=%s", groupId, format)
+	}
+
+	assert.Equal(t, 403, send(router, outsider, "GET", path(export.FormatJSON), nil).Code)
+	assert.Equal(t, 400, send(router, memberCookie, "GET", path("pdf"), nil).Code)
+
+	// Small exports are downloaded directly, oldest first
+	w = send(router, memberCookie, "GET", path(export.FormatJSON), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, export.Formats[export.FormatJSON], w.Header().Get("Content-Type"))
+
+	var transcript struct {
+		GroupID  int64          `json:"group_id"`
+		Messages []export.Entry `json:"messages"`
+	}
+
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &transcript))
+	assert.Equal(t, groupId, transcript.GroupID)
+
+	if assert.Len(t, transcript.Messages, 2) {
+		assert.Equal(t, first, transcript.Messages[0].ID)
+		assert.Equal(t, second, transcript.Messages[1].ID)
+		assert.Equal(t, &first, transcript.Messages[1].ParentID)
+	}
+
+	w = send(router, memberCookie, "GET", path(export.FormatCSV), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "message_id,iat,time,user_ident,display_name,parent_id,contents\n"))
+	assert.Contains(t, w.Body.String(), `"first, with a comma"`)
+
+	w = send(router, memberCookie, "GET", path(export.FormatHTML), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "<script>")
+	assert.Contains(t, w.Body.String(), "&lt;script&gt;")
+
+	w = send(router, memberCookie, "GET", path(export.FormatText), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf("(reply to #%d)", first))
+	assert.NotContains(t, w.Body.String(), "deleted")
+
+	w = send(router, memberCookie, "GET", fmt.Sprintf("%s&from=%d", path(export.FormatText), time.Now().Unix()+60), nil)
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "first")
+
+	// Larger exports run as a job, whose file only its member creator may fetch
+	job, err := export.Enqueue(export.FormatText, export.Query{UserID: account(t, memberIdent).ID, GroupID: groupId})
+	assert.Nil(t, err)
+
+	status := func(cookie *http.Cookie) (int, group.ExportJobResponse) {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/exports/%d", job.ID), nil)
+
+		var response group.ExportJobResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, _ := status(owner)
+	assert.Equal(t, 400, code)
+
+	for i := 0; i < 50; i++ {
+		if _, response := status(memberCookie); response.Status == export.StatusDone {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	file := fmt.Sprintf("/api/v1/group/exports/%d/file", job.ID)
+
+	w = send(router, memberCookie, "GET", file, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "first, with a comma")
+
+	w = send(router, owner, "POST", "/api/v1/group/kick", group.SanctionRequest{GroupID: groupId, Identifier: memberIdent, Reason: "Spam"})
+	assert.Equal(t, 200, w.Code)
+
+	code, _ = status(memberCookie)
+	assert.Equal(t, 403, code)
+	assert.Equal(t, 403, send(router, memberCookie, "GET", file, nil).Code)
+
+	// Jobs of users no longer in the group fail rather than run
+	job, err = export.Enqueue(export.FormatText, export.Query{UserID: account(t, memberIdent).ID, GroupID: groupId})
+	assert.Nil(t, err)
+
+	for i := 0; i < 50; i++ {
+		if job, _ = export.Get(job.UserID, job.ID); job.Status == export.StatusFailed {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	assert.Equal(t, export.StatusFailed, job.Status)
+}
+
+func TestScheduledMessages(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	later := time.Now().Unix() + 3600
+	hourly := int64(schedule.MinRepeatInterval)
+
+	create := func(cookie *http.Cookie, request group.ScheduleRequest) *httptest.ResponseRecorder {
+		request.GroupID = groupId
+		return send(router, cookie, "POST", "/api/v1/group/schedule", request)
+	}
+
+	assert.Equal(t, 403, create(outsider, group.ScheduleRequest{Contents: "hello", SendAt: later}).Code)
+	assert.Equal(t, 400, create(memberCookie, group.ScheduleRequest{Contents: "hello", SendAt: time.Now().Unix() - 60}).Code)
+	assert.Equal(t, 400, create(memberCookie, group.ScheduleRequest{Contents: " ", SendAt: later}).Code)
+	assert.Equal(t, 400, create(memberCookie, group.ScheduleRequest{Contents: "hello", SendAt: later, RepeatEvery: lo.ToPtr(hourly - 1)}).Code)
+	assert.Equal(t, 400, create(memberCookie, group.ScheduleRequest{Contents: "hello", SendAt: later, RepeatUntil: lo.ToPtr(later + hourly)}).Code)
+
+	w = create(memberCookie, group.ScheduleRequest{Contents: "reminder", SendAt: later})
+	assert.Equal(t, 200, w.Code)
+
+	var scheduled group.ScheduledResponseItem
+	json.Unmarshal(w.Body.Bytes(), &scheduled)
+	assert.Equal(t, memberIdent, scheduled.Identifier)
+
+	listing := func(cookie *http.Cookie, query string) (int, []group.ScheduledResponseItem) {
+		w := send(router, cookie, "GET", "/api/v1/group/scheduled?"+query, nil)
+
+		var response []group.ScheduledResponseItem
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, items := listing(memberCookie, fmt.Sprintf("group_id=%d", groupId))
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []group.ScheduledResponseItem{scheduled}, items)
+
+	code, items = listing(owner, fmt.Sprintf("group_id=%d", groupId))
+	assert.Equal(t, 200, code)
+	assert.Empty(t, items)
+
+	// Moderators may list and cancel what every member has scheduled
+	code, _ = listing(memberCookie, fmt.Sprintf("group_id=%d&all=true", groupId))
+	assert.Equal(t, 403, code)
+
+	code, _ = listing(owner, "all=true")
+	assert.Equal(t, 400, code)
+
+	code, items = listing(owner, fmt.Sprintf("group_id=%d&all=true", groupId))
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []group.ScheduledResponseItem{scheduled}, items)
+
+	w = send(router, owner, "POST", "/api/v1/group/reschedule", group.RescheduleRequest{ID: scheduled.ID, Contents: "changed", SendAt: later})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/reschedule", group.RescheduleRequest{ID: scheduled.ID, Contents: "changed", SendAt: later + 60})
+	assert.Equal(t, 200, w.Code)
+
+	_, outsiderItems := listing(outsider, "")
+	assert.Empty(t, outsiderItems)
+
+	w = send(router, outsider, "POST", "/api/v1/group/unschedule", group.UnscheduleRequest{ID: scheduled.ID})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/unschedule", group.UnscheduleRequest{ID: scheduled.ID})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/unschedule", group.UnscheduleRequest{ID: scheduled.ID})
+	assert.Equal(t, 400, w.Code)
+
+	// Messages are posted once due, and recurring ones are scheduled again
+	soon := time.Now().Unix() + 1
+
+	w = create(memberCookie, group.ScheduleRequest{Contents: "once", SendAt: soon})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/schedule", group.ScheduleRequest{GroupID: groupId, Contents: "hourly", SendAt: soon, RepeatEvery: &hourly})
+	assert.Equal(t, 200, w.Code)
+
+	var recurring group.ScheduledResponseItem
+	json.Unmarshal(w.Body.Bytes(), &recurring)
+
+	go schedule.Worker()
+
+	var contents []string
+	for deadline := time.Now().Add(schedule.PollInterval + 5*time.Second); time.Now().Before(deadline) && len(contents) < 2; time.Sleep(500 * time.Millisecond) {
+		w := send(router, owner, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=20", groupId), nil)
+
+		var history group.HistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &history)
+
+		contents = lo.FilterMap(history.Messages, func(x group.HistoryResponseItem, _ int) (string, bool) {
+			return x.Contents, x.Identifier == memberIdent || x.Identifier == ownerIdent
+		})
+	}
+
+	assert.ElementsMatch(t, []string{"once", "hourly"}, contents)
+
+	code, items = listing(memberCookie, "")
+	assert.Equal(t, 200, code)
+	assert.Empty(t, items)
+
+	code, items = listing(owner, "")
+	assert.Equal(t, 200, code)
+
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, recurring.ID, items[0].ID)
+		assert.Equal(t, recurring.SendAt+hourly, items[0].SendAt)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	globals.Opts.AttachmentFolderPath = t.TempDir()
+	globals.Opts.ArchiveFolderPath = t.TempDir()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	retain := func(cookie *http.Cookie, keep string, days int64) int {
+		return send(router, cookie, "POST", "/api/v1/group/retention", group.RetentionRequest{GroupID: groupId, Keep: keep, Days: days}).Code
+	}
+
+	assert.Equal(t, 403, retain(memberCookie, retention.KeepDays, 1))
+	assert.Equal(t, 400, retain(owner, retention.KeepDays, 0))
+	assert.Equal(t, 400, retain(owner, "week", 0))
+	assert.Equal(t, 200, retain(owner, retention.KeepDays, 1))
+
+	w = send(router, owner, "POST", "/api/v1/group/filter", group.FilterRequest{GroupID: groupId, Word: "cheat", Action: moderation.ActionFlag})
+	assert.Equal(t, 200, w.Code)
+
+	// Give an expiring message a row in every table that refers to messages
+	old := post(t, ownerIdent, groupId, "@"+memberIdent+" how to cheat", nil)
+
+	assert.Equal(t, 200, send(router, memberCookie, "POST", "/api/v1/group/react", group.ReactRequest{MessageID: old, Emoji: "👍"}).Code)
+	assert.Equal(t, 200, send(router, owner, "POST", "/api/v1/group/pin", group.PinRequest{MessageID: old}).Code)
+	assert.Equal(t, 200, send(router, memberCookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: old}).Code)
+	assert.Equal(t, 200, send(router, owner, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: old, Contents: "how to cheat, edited"}).Code)
+
+	w = send(router, owner, "GET", fmt.Sprintf("/api/v1/group/reports?group_id=%d&limit=20", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var reports []group.ReportsResponseItem
+	json.Unmarshal(w.Body.Bytes(), &reports)
+
+	if assert.Len(t, reports, 1) {
+		w = send(router, owner, "POST", "/api/v1/group/resolve", group.ResolveRequest{ReportID: reports[0].ID, Action: chat.ResolveWarn, Reason: "Rules"})
+		assert.Equal(t, 200, w.Code)
+	}
+
+	w = upload(router, memberCookie, groupId, "notes.txt", []byte("retention test notes"))
+	assert.Equal(t, 200, w.Code)
+
+	var attachment chat.Attachment
+	json.Unmarshal(w.Body.Bytes(), &attachment)
+
+	_, err := chat.Post(account(t, memberIdent), groupId, chat.Draft{Attachments: []int64{attachment.ID}}, nil)
+	assert.Nil(t, err)
+
+	var stored model.MessageAttachment
+	err = SELECT(MessageAttachment.Hash).FROM(MessageAttachment).WHERE(MessageAttachment.ID.EQ(Int64(attachment.ID))).Query(globals.Database, &stored)
+	assert.Nil(t, err)
+
+	poll, err := chat.Post(account(t, ownerIdent), groupId, chat.Draft{Contents: "which?", Poll: &chat.PollDraft{Options: []string{"a", "b"}}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, send(router, memberCookie, "POST", "/api/v1/group/vote", group.VoteRequest{MessageID: poll.ID, Options: []int64{poll.Poll.Options[0].ID}}).Code)
+
+	scheduled, err := schedule.Create(account(t, ownerIdent), groupId, schedule.Plan{Contents: "later", SendAt: time.Now().Unix() + 3600})
+	assert.Nil(t, err)
+
+	_, err = ScheduledMessage.UPDATE(ScheduledMessage.LastMessageID).SET(Int64(old)).WHERE(ScheduledMessage.ID.EQ(Int64(scheduled.ID))).Exec(globals.Database)
+	assert.Nil(t, err)
+
+	// Thread parents are kept while any of their replies are
+	parent := post(t, ownerIdent, groupId, "question", nil)
+	reply := post(t, memberIdent, groupId, "answer", &parent)
+
+	ids := func() []int64 {
+		var dest []model.RoomMessage
+		err := SELECT(RoomMessage.ID).FROM(RoomMessage).WHERE(RoomMessage.RoomID.EQ(Int64(groupId))).Query(globals.Database, &dest)
+		assert.Nil(t, err)
+		return lo.Map(dest, func(x model.RoomMessage, _ int) int64 { return x.ID })
+	}
+
+	age := func(cond BoolExpression) {
+		stmt := RoomMessage.UPDATE(RoomMessage.Iat).SET(Int64(time.Now().AddDate(0, 0, -2).Unix())).WHERE(RoomMessage.RoomID.EQ(Int64(groupId)).AND(cond))
+		_, err := stmt.Exec(globals.Database)
+		assert.Nil(t, err)
+	}
+
+	var room model.Room
+	err = SELECT(Room.ID, Room.Retention, Room.RetentionDays).FROM(Room).WHERE(Room.ID.EQ(Int64(groupId))).Query(globals.Database, &room)
+	assert.Nil(t, err)
+
+	expired := lo.Without(ids(), reply)
+	age(RoomMessage.ID.NOT_EQ(Int64(reply)))
+
+	n, err := retention.Purge(room, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, len(expired)-1, n)
+	assert.ElementsMatch(t, []int64{parent, reply}, ids())
+
+	purged := lo.Map(lo.Without(expired, parent), func(x int64, _ int) Expression { return Int64(x) })
+
+	for _, x := range []struct {
+		table  ReadableTable
+		column ColumnInteger
+	}{
+		{MessageReaction, MessageReaction.MessageID},
+		{MessageMention, MessageMention.MessageID},
+		{MessagePin, MessagePin.MessageID},
+		{MessageBookmark, MessageBookmark.MessageID},
+		{Notification, Notification.MessageID},
+		{MessageAttachment, MessageAttachment.MessageID},
+		{MessageReport, MessageReport.MessageID},
+		{RoomMessageRevision, RoomMessageRevision.MessageID},
+		{PollVote, PollVote.MessageID},
+		{PollOption, PollOption.MessageID},
+		{Poll, Poll.MessageID},
+		{ModerationLog, ModerationLog.MessageID},
+		{ModerationFlag, ModerationFlag.MessageID},
+		{ScheduledMessage, ScheduledMessage.LastMessageID},
+	} {
+		var count struct {
+			Count int64 `alias:"count"`
+		}
+
+		err := SELECT(COUNT(STAR).AS("count")).FROM(x.table).WHERE(x.column.IN(purged...)).Query(globals.Database, &count)
+		assert.Nil(t, err)
+		assert.Zero(t, count.Count, x.column.Name())
+	}
+
+	// Records kept for accountability only lose their link to the message
+	var logs []model.ModerationLog
+	err = SELECT(ModerationLog.AllColumns).FROM(ModerationLog).WHERE(ModerationLog.RoomID.EQ(Int64(groupId))).Query(globals.Database, &logs)
+	assert.Nil(t, err)
+
+	assert.NotEmpty(t, logs)
+	for _, x := range logs {
+		assert.Nil(t, x.MessageID)
+	}
+
+	_, err = os.Stat(chat.AttachmentPath(stored.Hash))
+	assert.True(t, os.IsNotExist(err))
+
+	archives, _ := filepath.Glob(filepath.Join(globals.Opts.ArchiveFolderPath, strconv.FormatInt(groupId, 10), "*.jsonl.gz"))
+	assert.NotEmpty(t, archives)
+
+	// Once their replies expire, parents go with them
+	age(RoomMessage.ID.EQ(Int64(reply)))
+
+	n, err = retention.Purge(room, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Empty(t, ids())
+
+	assert.Equal(t, 200, retain(owner, "default", 0))
+}
+
+func TestPolls(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ownerIdent, owner := register(t, router)
+	authorIdent, author := register(t, router)
+	voterIdent, voter := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	for _, cookie := range []*http.Cookie{author, voter} {
+		w := send(router, cookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+		assert.Equal(t, 200, w.Code)
+	}
+
+	ask := func(draft chat.PollDraft) (chat.Message, error) {
+		return chat.Post(account(t, authorIdent), groupId, chat.Draft{Contents: "which chapter?", Poll: &draft}, nil)
+	}
+
+	for _, draft := range []chat.PollDraft{
+		{Options: []string{"one"}},
+		{Options: []string{"one", "one"}},
+		{Options: []string{"one", " "}},
+		{Options: []string{"one", "two"}, ClosesAt: lo.ToPtr(time.Now().Unix() - 60)},
+	} {
+		_, err := ask(draft)
+		assert.Equal(t, chat.ErrPoll, err)
+	}
+
+	msg, err := ask(chat.PollDraft{Options: []string{"one", "two"}})
+	assert.Nil(t, err)
+
+	other, err := ask(chat.PollDraft{Options: []string{"three", "four"}, Multiple: true, Anonymous: true})
+	assert.Nil(t, err)
+
+	one, two := msg.Poll.Options[0].ID, msg.Poll.Options[1].ID
+
+	vote := func(cookie *http.Cookie, id int64, options ...int64) int {
+		return send(router, cookie, "POST", "/api/v1/group/vote", group.VoteRequest{MessageID: id, Options: options}).Code
+	}
+
+	poll := func(cookie *http.Cookie, id int64) chat.PollState {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=20", groupId), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.HistoryResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+
+		item, _ := lo.Find(response.Messages, func(x group.HistoryResponseItem) bool { return x.ID == id })
+		if assert.NotNil(t, item.Poll) {
+			return *item.Poll
+		}
+
+		return chat.PollState{}
+	}
+
+	conn := dial(t, server, owner, groupId)
+	defer conn.Close()
+
+	assert.Equal(t, 403, vote(outsider, msg.ID, one))
+	assert.Equal(t, 400, vote(voter, msg.ID, one, two))
+	assert.Equal(t, 400, vote(voter, msg.ID, other.Poll.Options[0].ID))
+
+	assert.Equal(t, 200, vote(voter, msg.ID, one))
+	assert.Equal(t, 200, vote(owner, msg.ID, two))
+	assert.Equal(t, 200, vote(voter, msg.ID, two))
+
+	var frame chat.PollEvent
+	for frame.Type != chat.EventPollUpdated {
+		assert.Nil(t, conn.ReadJSON(&frame))
+	}
+
+	assert.Equal(t, msg.ID, frame.ID)
+
+	state := poll(voter, msg.ID)
+	assert.Equal(t, int64(2), state.Voters)
+	assert.Equal(t, int64(0), state.Options[0].Votes)
+	assert.Equal(t, int64(2), state.Options[1].Votes)
+	assert.True(t, state.Options[1].Voted)
+	assert.ElementsMatch(t, []string{ownerIdent, voterIdent}, state.Options[1].Voters)
+
+	assert.Equal(t, 200, vote(voter, msg.ID))
+	assert.Equal(t, int64(1), poll(voter, msg.ID).Voters)
+	assert.False(t, poll(voter, msg.ID).Options[1].Voted)
+
+	// Voters are not listed on anonymous polls, which may allow several choices
+	assert.Equal(t, 200, vote(voter, other.ID, other.Poll.Options[0].ID, other.Poll.Options[1].ID))
+
+	state = poll(owner, other.ID)
+	assert.Equal(t, int64(1), state.Voters)
+	assert.Equal(t, int64(1), state.Options[0].Votes)
+	assert.Empty(t, state.Options[0].Voters)
+
+	// Polls may be closed by their author or a moderator
+	closePoll := func(cookie *http.Cookie, id int64) int {
+		return send(router, cookie, "POST", "/api/v1/group/closepoll", group.ClosePollRequest{MessageID: id}).Code
+	}
+
+	assert.Equal(t, 403, closePoll(voter, msg.ID))
+	assert.Equal(t, 200, closePoll(author, msg.ID))
+	assert.Equal(t, 409, closePoll(author, msg.ID))
+	assert.Equal(t, 200, closePoll(owner, other.ID))
+
+	assert.NotNil(t, poll(voter, msg.ID).ClosedAt)
+	assert.Equal(t, 409, vote(voter, msg.ID, one))
+}
+
+func TestDirectMessages(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	firstIdent, first := register(t, router)
+	secondIdent, second := register(t, router)
+	thirdIdent, third := register(t, router)
+
+	open := func(cookie *http.Cookie, idents ...string) (int, int64) {
+		w := send(router, cookie, "POST", "/api/v1/dm/open/"+strings.Join(idents, ","), nil)
+
+		id, _ := strconv.ParseInt(w.Body.String(), 10, 64)
+		return w.Code, id
+	}
+
+	code, pair := open(first, secondIdent)
+	assert.Equal(t, 200, code)
+
+	// Conversations are found by their participants
+	code, id := open(second, firstIdent)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, pair, id)
+
+	code, trio := open(first, secondIdent, thirdIdent)
+	assert.Equal(t, 200, code)
+	assert.NotEqual(t, pair, trio)
+
+	code, _ = open(first, firstIdent)
+	assert.Equal(t, 400, code)
+
+	code, _ = open(first, "unknown")
+	assert.Equal(t, 400, code)
+
+	// Only participants may read or post
+	w := send(router, third, "GET", fmt.Sprintf("/api/v1/group/history/%d", pair), nil)
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, third, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: pair})
+	assert.Equal(t, 403, w.Code)
+
+	_, err := chat.Post(account(t, thirdIdent), pair, chat.Draft{Contents: "hello"}, nil)
+	assert.Equal(t, chat.ErrNotMember, err)
+
+	sender := dial(t, server, first, pair)
+	defer sender.Close()
+
+	receiver := dial(t, server, second, pair)
+	defer receiver.Close()
+
+	assert.Nil(t, sender.WriteMessage(websocket.TextMessage, []byte("hello")))
+
+	var msg chat.Message
+	assert.Nil(t, receiver.ReadJSON(&msg))
+	assert.Equal(t, chat.EventMessageCreated, msg.Type)
+	assert.Equal(t, firstIdent, msg.Identifier)
+	assert.Equal(t, "hello", msg.Contents)
+
+	// Blocking in either direction closes every conversation between them
+	w = send(router, second, "POST", "/api/v1/user/block", user.BlockRequest{Identifier: firstIdent})
+	assert.Equal(t, 200, w.Code)
+
+	code, _ = open(first, secondIdent)
+	assert.Equal(t, 403, code)
+
+	code, _ = open(second, firstIdent)
+	assert.Equal(t, 403, code)
+
+	code, _ = open(third, firstIdent, secondIdent)
+	assert.Equal(t, 403, code)
+
+	_, err = chat.Post(account(t, secondIdent), pair, chat.Draft{Contents: "hello"}, nil)
+	assert.Equal(t, member.ErrBlocked, err)
+
+	_, err = chat.Post(account(t, thirdIdent), trio, chat.Draft{Contents: "hello"}, nil)
+	assert.Equal(t, member.ErrBlocked, err)
+
+	assert.Nil(t, sender.WriteJSON(map[string]string{"type": "message.send", "contents": "hello again"}))
+
+	var frame struct {
+		Type  string `json:"type"`
+		Error string `json:"error"`
+	}
+
+	assert.Nil(t, sender.ReadJSON(&frame))
+	assert.Equal(t, "error", frame.Type)
+	assert.Equal(t, member.ErrBlocked.Error(), frame.Error)
+
+	w = send(router, second, "POST", "/api/v1/user/unblock", user.BlockRequest{Identifier: firstIdent})
+	assert.Equal(t, 200, w.Code)
+
+	code, id = open(first, secondIdent)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, pair, id)
+
+	_, err = chat.Post(account(t, firstIdent), pair, chat.Draft{Contents: "hello again"}, nil)
+	assert.Nil(t, err)
+}
+
+func TestMembers(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	modIdent, mod := register(t, router)
+	aliceIdent, alice := register(t, router)
+	bobIdent, bob := register(t, router)
+	carlIdent, carl := register(t, router)
+	_, outsider := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	for cookie, name := range map[*http.Cookie]string{owner: "Zoe", mod: "Yan", alice: "alice", bob: "Bob", carl: "Carl"} {
+		w := send(router, cookie, "POST", "/api/v1/user/display_name", user.DisplayNameRequest{DisplayName: name})
+		assert.Equal(t, 200, w.Code)
+
+		if cookie != owner {
+			w = send(router, cookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+			assert.Equal(t, 200, w.Code)
+		}
+	}
+
+	promote(t, modIdent, groupId)
+
+	assert.Equal(t, 200, send(router, bob, "POST", "/api/v1/user/privacy", user.PrivacyRequest{Privacy: member.PrivacyMembers}).Code)
+	assert.Equal(t, 200, send(router, carl, "POST", "/api/v1/user/privacy", user.PrivacyRequest{Privacy: member.PrivacyHidden}).Code)
+
+	members := func(cookie *http.Cookie, query string) group.MembersResponse {
+		w := send(router, cookie, "GET", fmt.Sprintf("/api/v1/group/members/%d?%s", groupId, query), nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response group.MembersResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	idents := func(response group.MembersResponse) []string {
+		return lo.Map(response.Members, func(x group.MembersResponseItem, _ int) string { return x.Identifier })
+	}
+
+	// Owners come first, then moderators, then everyone else by name
+	response := members(alice, "")
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent, bobIdent}, idents(response))
+
+	if assert.Len(t, response.Members, 4) {
+		assert.Equal(t, member.RoleOwner, response.Members[0].Role)
+		assert.Equal(t, member.RoleModerator, response.Members[1].Role)
+		assert.Equal(t, member.RoleMember, response.Members[2].Role)
+		assert.Equal(t, "Yan", response.Members[1].DisplayName)
+		assert.True(t, strings.HasSuffix(response.Members[2].AvatarURL, "/user/profile_picture/"+aliceIdent))
+	}
+
+	// Privacy settings hide members from those outside the group
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent}, idents(members(outsider, "")))
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent}, idents(members(nil, "")))
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent, bobIdent, carlIdent}, idents(members(carl, "")))
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent, bobIdent, carlIdent}, idents(members(mod, "")))
+
+	// Members who have blocked the user are left out
+	assert.Equal(t, 200, send(router, alice, "POST", "/api/v1/user/block", user.BlockRequest{Identifier: bobIdent}).Code)
+	assert.Equal(t, []string{ownerIdent, modIdent, bobIdent}, idents(members(bob, "")))
+
+	// Paging visits every member once
+	var paged []string
+	page := members(owner, "limit=2")
+	for {
+		paged = append(paged, idents(page)...)
+		if page.Next == nil {
+			break
+		}
+
+		page = members(owner, "limit=2&cursor="+*page.Next)
+	}
+
+	assert.Equal(t, []string{ownerIdent, modIdent, aliceIdent, bobIdent, carlIdent}, paged)
+
+	w := send(router, owner, "GET", fmt.Sprintf("/api/v1/group/members/%d?cursor=bad", groupId), nil)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestSystemMessages(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	conn := dial(t, server, owner, groupId)
+	defer conn.Close()
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	var frame chat.Message
+	assert.Nil(t, conn.ReadJSON(&frame))
+	assert.Equal(t, chat.EventMessageCreated, frame.Type)
+	assert.Equal(t, chat.KindSystem, frame.Kind)
+	assert.Empty(t, frame.Identifier)
+
+	if assert.NotNil(t, frame.System) {
+		assert.Equal(t, chat.SystemJoined, frame.System.Event)
+		assert.Equal(t, memberIdent, frame.System.Identifier)
+	}
+
+	joined := frame.ID
+
+	// Descriptions of any allowed length are carried whole
+	description := strings.Repeat("d", group.MaxDescriptionLength)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/describe", group.DescribeRequest{GroupID: groupId, Description: "mine"})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/describe", group.DescribeRequest{GroupID: groupId, Description: description})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/leave", user.LeaveRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, nil, "GET", fmt.Sprintf("/api/v1/group/history/%d?limit=20", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var history group.HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+
+	events := lo.FilterMap(history.Messages, func(x group.HistoryResponseItem, _ int) (chat.SystemEvent, bool) {
+		return lo.FromPtr(x.System), x.Kind == chat.KindSystem && x.System != nil
+	})
+
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, chat.SystemLeft, events[0].Event)
+		assert.Equal(t, memberIdent, events[0].Identifier)
+
+		assert.Equal(t, chat.SystemDescription, events[1].Event)
+		assert.Equal(t, ownerIdent, events[1].Moderator)
+		assert.Equal(t, &description, events[1].Description)
+
+		assert.Equal(t, chat.SystemJoined, events[2].Event)
+	}
+
+	// System messages have no author to edit, reply to, or report them
+	w = send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/edit", group.EditRequest{MessageID: joined, Contents: "changed"})
+	assert.Equal(t, 403, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/group/report", group.ReportRequest{MessageID: joined, Reason: "Spam"})
+	assert.Equal(t, 400, w.Code)
+
+	_, err := chat.Post(account(t, memberIdent), groupId, chat.Draft{Contents: "reply", ParentID: &joined}, nil)
+	assert.Equal(t, chat.ErrParent, err)
+
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: joined})
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPostingPolicy(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	// Only moderators set the policy, and only within its limits
+	w = send(router, memberCookie, "POST", "/api/v1/group/posting", group.PostingRequest{GroupID: groupId, SlowMode: 60})
+	assert.Equal(t, 403, w.Code)
+
+	for _, request := range []group.PostingRequest{
+		{GroupID: groupId, SlowMode: -1},
+		{GroupID: groupId, SlowMode: chat.MaxSlowMode + 1},
+		{GroupID: groupId, MemberDays: -1},
+		{GroupID: groupId, MemberDays: chat.MaxMemberDays + 1},
+	} {
+		w = send(router, owner, "POST", "/api/v1/group/posting", request)
+		assert.Equal(t, 400, w.Code)
+	}
+
+	w = send(router, owner, "POST", "/api/v1/group/posting", group.PostingRequest{GroupID: groupId, SlowMode: 60})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, nil, "GET", fmt.Sprintf("/api/v1/group/get/%d", groupId), nil)
+	assert.Equal(t, 200, w.Code)
+
+	var info group.GetResponse
+	json.Unmarshal(w.Body.Bytes(), &info)
+	assert.Equal(t, chat.Policy{SlowMode: 60}, info.Posting)
+
+	// Slow mode makes members wait between messages
+	post(t, memberIdent, groupId, "first", nil)
+
+	var restriction *chat.Restriction
+
+	_, err := chat.Post(account(t, memberIdent), groupId, chat.Draft{Contents: "second"}, nil)
+	if assert.ErrorAs(t, err, &restriction) && assert.NotNil(t, restriction.RetryAt) {
+		assert.InDelta(t, time.Now().Unix()+60, *restriction.RetryAt, 2)
+	}
+
+	conn := dial(t, server, memberCookie, groupId)
+	defer conn.Close()
+
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte("third")))
+
+	var frame struct {
+		Type    string `json:"type"`
+		Error   string `json:"error"`
+		RetryAt *int64 `json:"retry_at"`
+	}
+
+	assert.Nil(t, conn.ReadJSON(&frame))
+	assert.Equal(t, "error", frame.Type)
+	assert.NotNil(t, frame.RetryAt)
+
+	// Moderators are exempt
+	post(t, ownerIdent, groupId, "one", nil)
+	post(t, ownerIdent, groupId, "two", nil)
+
+	// Announcement-only groups refuse members without a time to retry at
+	w = send(router, owner, "POST", "/api/v1/group/posting", group.PostingRequest{GroupID: groupId, AnnouncementOnly: true})
+	assert.Equal(t, 200, w.Code)
+
+	_, err = chat.Post(account(t, memberIdent), groupId, chat.Draft{Contents: "hello"}, nil)
+	if assert.ErrorAs(t, err, &restriction) {
+		assert.Nil(t, restriction.RetryAt)
+	}
+
+	post(t, ownerIdent, groupId, "announcement", nil)
+
+	// New members wait the given number of days after joining
+	w = send(router, owner, "POST", "/api/v1/group/posting", group.PostingRequest{GroupID: groupId, MemberDays: 2})
+	assert.Equal(t, 200, w.Code)
+
+	_, err = chat.Post(account(t, memberIdent), groupId, chat.Draft{Contents: "hello"}, nil)
+	if assert.ErrorAs(t, err, &restriction) && assert.NotNil(t, restriction.RetryAt) {
+		assert.Greater(t, *restriction.RetryAt, time.Now().Unix()+24*60*60)
+	}
+
+	stmt := UserRoom.UPDATE(UserRoom.JoinedAt).SET(Int64(time.Now().Add(-72 * time.Hour).Unix())).WHERE(
+		UserRoom.UserID.EQ(Int64(account(t, memberIdent).ID)).AND(UserRoom.RoomID.EQ(Int64(groupId))),
+	)
+
+	_, err = stmt.Exec(globals.Database)
+	assert.Nil(t, err)
+
+	post(t, memberIdent, groupId, "hello", nil)
+
+	// Outsiders may post to public groups, but not once membership is required
+	outsiderIdent, _ := register(t, router)
+
+	_, err = chat.Post(account(t, outsiderIdent), groupId, chat.Draft{Contents: "hi"}, nil)
+	if assert.ErrorAs(t, err, &restriction) {
+		assert.Nil(t, restriction.RetryAt)
+	}
+
+	w = send(router, owner, "POST", "/api/v1/group/posting", group.PostingRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	post(t, outsiderIdent, groupId, "hi", nil)
+}
+
+func TestBookmarks(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	ownerIdent, owner := register(t, router)
+	_, cookie := register(t, router)
+
+	publicId := createGroup(t, router, owner, member.VisibilityPublic)
+	privateId := createGroup(t, router, owner, member.VisibilityPrivate)
+
+	first := post(t, ownerIdent, publicId, "first", nil)
+	second := post(t, ownerIdent, publicId, "second", nil)
+	secret := post(t, ownerIdent, privateId, "secret", nil)
+
+	// Notes are trimmed and limited in length
+	w := send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: first, Note: strings.Repeat("n", chat.MaxNoteLength+1)})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: first, Note: "  old  "})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: first, Note: " read later "})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: second})
+	assert.Equal(t, 200, w.Code)
+
+	// Messages in private groups can only be bookmarked by members
+	w = send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: secret})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/invite", group.InviteRequest{GroupID: privateId})
+	assert.Equal(t, 200, w.Code)
+
+	var invite group.InviteResponseItem
+	json.Unmarshal(w.Body.Bytes(), &invite)
+
+	w = send(router, cookie, "POST", "/api/v1/group/invite/accept/"+invite.Code, nil)
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, cookie, "POST", "/api/v1/group/bookmark", group.BookmarkRequest{MessageID: secret})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, nil, "GET", "/api/v1/user/bookmarks", nil)
+	assert.Equal(t, 401, w.Code)
+
+	w = send(router, cookie, "GET", "/api/v1/user/bookmarks", nil)
+	assert.Equal(t, 200, w.Code)
+
+	var res user.BookmarksResponse
+	json.Unmarshal(w.Body.Bytes(), &res)
+
+	assert.Nil(t, res.Next)
+	if assert.Len(t, res.Bookmarks, 3) {
+		assert.Equal(t, secret, res.Bookmarks[0].ID)
+		assert.Equal(t, privateId, res.Bookmarks[0].GroupID)
+
+		assert.Equal(t, second, res.Bookmarks[1].ID)
+		assert.Nil(t, res.Bookmarks[1].Note)
+
+		assert.Equal(t, first, res.Bookmarks[2].ID)
+		assert.Equal(t, ownerIdent, res.Bookmarks[2].Identifier)
+		assert.Equal(t, "first", res.Bookmarks[2].Contents)
+		assert.Equal(t, lo.ToPtr("read later"), res.Bookmarks[2].Note)
+	}
+
+	// Pages follow the cursor
+	w = send(router, cookie, "GET", "/api/v1/user/bookmarks?limit=2", nil)
+	assert.Equal(t, 200, w.Code)
+
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Len(t, res.Bookmarks, 2)
+
+	if assert.NotNil(t, res.Next) {
+		w = send(router, cookie, "GET", "/api/v1/user/bookmarks?limit=2&cursor="+*res.Next, nil)
+		assert.Equal(t, 200, w.Code)
+
+		var page user.BookmarksResponse
+		json.Unmarshal(w.Body.Bytes(), &page)
+
+		assert.Nil(t, page.Next)
+		if assert.Len(t, page.Bookmarks, 1) {
+			assert.Equal(t, first, page.Bookmarks[0].ID)
+		}
+	}
+
+	w = send(router, cookie, "GET", "/api/v1/user/bookmarks?cursor=invalid", nil)
+	assert.Equal(t, 400, w.Code)
+
+	// Leaving a private group hides its bookmarks, and deleted messages are dropped
+	w = send(router, cookie, "POST", "/api/v1/user/leave", user.LeaveRequest{GroupID: privateId})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: second})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, cookie, "GET", "/api/v1/user/bookmarks", nil)
+	assert.Equal(t, 200, w.Code)
+
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, []int64{first}, lo.Map(res.Bookmarks, func(x user.BookmarksResponseItem, _ int) int64 { return x.ID }))
+
+	var count struct {
+		Count int64 `alias:"count"`
+	}
+
+	stmt := SELECT(COUNT(STAR).AS("count")).FROM(MessageBookmark).WHERE(MessageBookmark.MessageID.EQ(Int64(second)))
+	assert.Nil(t, stmt.Query(globals.Database, &count))
+	assert.Zero(t, count.Count)
+
+	// Only existing bookmarks can be removed
+	w = send(router, cookie, "POST", "/api/v1/group/unbookmark", group.UnbookmarkRequest{MessageID: first})
+	assert.Equal(t, 200, w.Code)
+
+	w = send(router, cookie, "POST", "/api/v1/group/unbookmark", group.UnbookmarkRequest{MessageID: first})
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestInbox(t *testing.T) {
+	router := setupRouter()
+	globals.Database = setupDatabase()
+	defer globals.Database.Close()
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ownerIdent, owner := register(t, router)
+	memberIdent, memberCookie := register(t, router)
+
+	groupId := createGroup(t, router, owner, member.VisibilityPublic)
+	otherId := createGroup(t, router, owner, member.VisibilityPublic)
+
+	w := send(router, memberCookie, "POST", "/api/v1/user/join", user.JoinRequest{GroupID: groupId})
+	assert.Equal(t, 200, w.Code)
+
+	inboxOf := func(cookie *http.Cookie, query string) user.NotificationsResponse {
+		w := send(router, cookie, "GET", "/api/v1/user/notifications"+query, nil)
+		assert.Equal(t, 200, w.Code)
+
+		var response user.NotificationsResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	// Notifications are pushed to sockets on any group
+	conn := dial(t, server, memberCookie, otherId)
+	defer conn.Close()
+
+	thread := post(t, memberIdent, groupId, "question", nil)
+	mention := post(t, ownerIdent, groupId, "@"+memberIdent+" "+strings.Repeat("a", inbox.MaxBodyLength), nil)
+
+	var frame inbox.Item
+	assert.Nil(t, conn.ReadJSON(&frame))
+	assert.Equal(t, inbox.EventNotification, frame.Type)
+	assert.Equal(t, inbox.KindMention, frame.Kind)
+	assert.Equal(t, ownerIdent, frame.Actor)
+	assert.Equal(t, &groupId, frame.GroupID)
+	assert.Equal(t, &mention, frame.MessageID)
+	assert.Len(t, []rune(frame.Body), inbox.MaxBodyLength)
+	assert.Nil(t, frame.ReadAt)
+
+	reply := post(t, ownerIdent, groupId, "answer", &thread)
+
+	assert.Nil(t, conn.ReadJSON(&frame))
+	assert.Equal(t, inbox.KindReply, frame.Kind)
+	assert.Equal(t, &reply, frame.MessageID)
+	assert.Equal(t, "answer", frame.Body)
+
+	w = send(router, nil, "GET", "/api/v1/user/notifications", nil)
+	assert.Equal(t, 401, w.Code)
+
+	res := inboxOf(memberCookie, "")
+	assert.Equal(t, int64(2), res.Unread)
+	if assert.Len(t, res.Notifications, 2) {
+		assert.Equal(t, inbox.KindReply, res.Notifications[0].Kind)
+		assert.Equal(t, inbox.KindMention, res.Notifications[1].Kind)
+		assert.Empty(t, res.Notifications[0].Type)
+	}
+
+	replyItem, mentionItem := res.Notifications[0].ID, res.Notifications[1].ID
+
+	// Pages go back from an item
+	res = inboxOf(memberCookie, "?limit=1")
+	assert.Equal(t, []int64{replyItem}, lo.Map(res.Notifications, func(x inbox.Item, _ int) int64 { return x.ID }))
+
+	res = inboxOf(memberCookie, fmt.Sprintf("?limit=1&before=%d", replyItem))
+	assert.Equal(t, []int64{mentionItem}, lo.Map(res.Notifications, func(x inbox.Item, _ int) int64 { return x.ID }))
+
+	// Users may only mark their own notifications read
+	w = send(router, owner, "POST", "/api/v1/user/notifications/read", user.ReadNotificationsRequest{IDs: []int64{mentionItem}})
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, int64(2), inboxOf(memberCookie, "").Unread)
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/notifications/read", user.ReadNotificationsRequest{IDs: make([]int64, user.MaxReadIDs+1)})
+	assert.Equal(t, 400, w.Code)
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/notifications/read", user.ReadNotificationsRequest{IDs: []int64{mentionItem, mentionItem}})
+	assert.Equal(t, 200, w.Code)
+
+	res = inboxOf(memberCookie, "?unread=true")
+	assert.Equal(t, int64(1), res.Unread)
+	assert.Equal(t, []int64{replyItem}, lo.Map(res.Notifications, func(x inbox.Item, _ int) int64 { return x.ID }))
+
+	res = inboxOf(memberCookie, "")
+	if assert.Len(t, res.Notifications, 2) {
+		assert.NotNil(t, res.Notifications[1].ReadAt)
+	}
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/notifications/read_all", nil)
+	assert.Equal(t, 200, w.Code)
+
+	res = inboxOf(memberCookie, "?unread=true")
+	assert.Zero(t, res.Unread)
+	assert.Empty(t, res.Notifications)
+
+	// Notifications from blocked users are hidden and not counted
+	latest := post(t, ownerIdent, groupId, "@"+memberIdent+" again", nil)
+	assert.Equal(t, int64(1), inboxOf(memberCookie, "").Unread)
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/block", user.BlockRequest{Identifier: ownerIdent})
+	assert.Equal(t, 200, w.Code)
+
+	res = inboxOf(memberCookie, "")
+	assert.Zero(t, res.Unread)
+	assert.Empty(t, res.Notifications)
+
+	w = send(router, memberCookie, "POST", "/api/v1/user/unblock", user.BlockRequest{Identifier: ownerIdent})
+	assert.Equal(t, 200, w.Code)
+
+	assert.Equal(t, int64(1), inboxOf(memberCookie, "").Unread)
+
+	// Deleting a message withdraws its notifications
+	w = send(router, owner, "POST", "/api/v1/group/delete", group.DeleteRequest{MessageID: latest})
+	assert.Equal(t, 200, w.Code)
+
+	res = inboxOf(memberCookie, "")
+	assert.Zero(t, res.Unread)
+	assert.False(t, lo.ContainsBy(res.Notifications, func(x inbox.Item) bool { return x.MessageID != nil && *x.MessageID == latest }))
+}
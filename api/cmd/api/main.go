@@ -13,8 +13,12 @@ import (
 	docs "github.com/tetrago/motmot/api/docs"
 	"github.com/tetrago/motmot/api/internal/auth"
 	"github.com/tetrago/motmot/api/internal/course"
+	"github.com/tetrago/motmot/api/internal/dm"
+	"github.com/tetrago/motmot/api/internal/export"
 	"github.com/tetrago/motmot/api/internal/globals"
 	"github.com/tetrago/motmot/api/internal/group"
+	"github.com/tetrago/motmot/api/internal/retention"
+	"github.com/tetrago/motmot/api/internal/schedule"
 	"github.com/tetrago/motmot/api/internal/user"
 	"github.com/tetrago/motmot/api/internal/ws"
 )
@@ -61,6 +65,7 @@ func setupRouter() *gin.Engine {
 
 	auth.HttpHandler(g)
 	course.HttpHandler(g)
+	dm.HttpHandler(g)
 	group.HttpHandler(g)
 	user.HttpHandler(g)
 	ws.HttpHandler(g)
@@ -77,6 +82,10 @@ func main() {
 	globals.Database = setupDatabase()
 	defer globals.Database.Close()
 
+	go export.Worker()
+	go schedule.Worker()
+	go retention.Worker()
+
 	r := setupRouter()
 	r.Run(fmt.Sprintf(":%d", globals.Opts.Port))
 }
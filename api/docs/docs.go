@@ -120,47 +120,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/group/all": {
-            "get": {
-                "description": "Gets all public groups",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "group"
-                ],
-                "summary": "Gets groups",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/group.AllResponseItem"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/group/get/{id}": {
-            "get": {
-                "description": "Gets group information",
+        "/dm/open/{ident}": {
+            "post": {
+                "description": "Gets (or creates) the group of a direct conversation between the user and others, which is refused if any participant has blocked another. The group is used like any other, for history and over a WebSocket.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "group"
+                    "dm"
                 ],
-                "summary": "Get group",
+                "summary": "Open direct conversation",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Group ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Comma-separated identifiers of the other participants",
+                        "name": "ident",
                         "in": "path",
                         "required": true
                     }
@@ -169,58 +143,41 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/group.GetResponse"
+                            "type": "integer"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/group/history/{id}": {
+        "/group/all": {
             "get": {
-                "description": "Gets message history from a group in descending order",
+                "description": "Gets all public groups",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "group"
                 ],
-                "summary": "Gets group messages",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Max number of messages to retreive (\u003c= 20)",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "UTC time cutoff; searches in reverse from this point (inclusive)",
-                        "name": "before",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
+                "summary": "Gets groups",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/group.HistoryResponseItem"
+                                "$ref": "#/definitions/group.AllResponseItem"
                             }
                         }
                     },
@@ -230,22 +187,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/group/popular/{count}": {
-            "get": {
-                "description": "Gets the most popular groups by member count",
+        "/group/attachment": {
+            "post": {
+                "description": "Uploads a file to a group to be attached to a message. Uploads not attached within a day are discarded.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "group"
                 ],
-                "summary": "Gets popular groups",
+                "summary": "Upload attachment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Count of groups to return",
-                        "name": "count",
-                        "in": "path",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to attach",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     }
                 ],
@@ -253,60 +217,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/group.PopularResponseItem"
-                            }
+                            "$ref": "#/definitions/chat.Attachment"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/group/search/{id}": {
+        "/group/attachment/{id}": {
             "get": {
-                "description": "Searches for a message in a group",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Downloads a file attached to a message in a group the user belongs to",
                 "tags": [
                     "group"
                 ],
-                "summary": "Searchs messages",
+                "summary": "Download attachment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Group ID",
+                        "description": "Attachment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content to search messages for",
-                        "name": "content",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Max number of messages to retreive (\u003c= 20)",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/group.SearchResponseItem"
-                            }
-                        }
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -314,24 +276,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/bio": {
+        "/group/ban": {
             "post": {
-                "description": "Updates a user's bio",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Removes a user from a group, closes their sockets on it, and prevents them from rejoining for the duration in seconds, or until lifted if zero",
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Updates bio",
+                "summary": "Ban user from group",
                 "parameters": [
                     {
-                        "description": "New bio",
+                        "description": "User to ban",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.BioRequest"
+                            "$ref": "#/definitions/group.SanctionRequest"
                         }
                     }
                 ],
@@ -345,27 +304,30 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/block": {
+        "/group/bookmark": {
             "post": {
-                "description": "Blocks a user's messages from being displayed",
+                "description": "Saves a message for the user with an optional note (\u003c= 256 characters). Bookmarking a message again replaces its note.",
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Block user",
+                "summary": "Bookmark message",
                 "parameters": [
                     {
-                        "description": "User to block",
+                        "description": "Message to bookmark",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.BlockRequest"
+                            "$ref": "#/definitions/group.BookmarkRequest"
                         }
                     }
                 ],
@@ -385,50 +347,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/blocked": {
-            "get": {
-                "description": "Returns all blocked users",
-                "tags": [
-                    "user"
-                ],
-                "summary": "Get blocked users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/user/display_name": {
+        "/group/closepoll": {
             "post": {
-                "description": "Updates a user's display name",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Stops a poll from accepting votes. Only the author or a moderator of the group may close a poll.",
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Updates display name",
+                "summary": "Close poll",
                 "parameters": [
                     {
-                        "description": "New display name",
+                        "description": "Poll to close",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.DisplayNameRequest"
+                            "$ref": "#/definitions/group.ClosePollRequest"
                         }
                     }
                 ],
@@ -442,36 +375,45 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/email": {
+        "/group/create": {
             "post": {
-                "description": "Updates a user's email",
+                "description": "Creates a study group owned by the user, optionally under a course group. Public groups are listed to everyone, unlisted groups only to those given their ID, and private groups may only be read and joined by invite.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Updates email",
+                "summary": "Create group",
                 "parameters": [
                     {
-                        "description": "New email",
+                        "description": "Group to create",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.EmailRequest"
+                            "$ref": "#/definitions/group.CreateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "integer"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -485,57 +427,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/get/{ident}": {
-            "get": {
-                "description": "Fetches publically available user information and groups.",
-                "produces": [
-                    "application/json"
-                ],
+        "/group/delete": {
+            "post": {
+                "description": "Deletes a message, keeping the previous contents as a revision. Authors may not delete while muted, banned, or after leaving the group.",
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Fetch user",
+                "summary": "Delete message",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User identifier",
-                        "name": "ident",
-                        "in": "path",
-                        "required": true
+                        "description": "Message to delete",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.DeleteRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/user.GetResponse"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/user/groups": {
-            "get": {
-                "description": "Returns all groups a user belongs to",
-                "tags": [
-                    "user"
-                ],
-                "summary": "Get groups",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/user.GroupsResponseItem"
-                            }
-                        }
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -543,21 +464,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/join": {
+        "/group/describe": {
             "post": {
-                "description": "Adds a user to a group",
+                "description": "Replaces the description of a group and announces the change to it",
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Join group",
+                "summary": "Change group description",
                 "parameters": [
                     {
-                        "description": "Group to join",
+                        "description": "New description",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.JoinRequest"
+                            "$ref": "#/definitions/group.DescribeRequest"
                         }
                     }
                 ],
@@ -571,70 +492,226 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/leave": {
+        "/group/edit": {
             "post": {
-                "description": "Removes a user from a group",
+                "description": "Replaces the contents of a message, keeping the previous contents as a revision. Authors may not edit while muted, banned, or after leaving the group.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Leave group",
+                "summary": "Edit message",
                 "parameters": [
                     {
-                        "description": "Group to leave",
+                        "description": "Message to edit",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.JoinRequest"
+                            "$ref": "#/definitions/group.EditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chat.Message"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/export/{id}": {
+            "get": {
+                "description": "Exports the messages of a group, oldest first, leaving out deleted messages and those from blocked users. Small exports are downloaded directly; larger ones respond with a job to poll until its file is ready.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Export group messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "One of json (default), csv, txt, or html",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include messages sent at or after this UTC time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include messages sent at or before this UTC time",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK"
                     },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/group.ExportJobResponse"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request"
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/password": {
+        "/group/exports/{id}": {
+            "get": {
+                "description": "Gets the status of an export job created by the user, who must still be a member of its group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get export job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.ExportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/exports/{id}/file": {
+            "get": {
+                "description": "Downloads the file produced by a finished export job. The user must still be a member of its group.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Download export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/filter": {
             "post": {
-                "description": "Updates a user's password",
+                "description": "Adds a moderation rule to a group. The action is one of mask, flag, or reject.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Updates password",
+                "summary": "Add group word filter",
                 "parameters": [
                     {
-                        "description": "New password",
+                        "description": "Rule to add",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.PasswordRequest"
+                            "$ref": "#/definitions/group.FilterRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.FiltersResponseItem"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -642,31 +719,43 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/profile_picture": {
-            "post": {
-                "description": "Uploads a new profile picture, replacing the old one",
+        "/group/filters/{id}": {
+            "get": {
+                "description": "Gets the moderation rules specific to a group",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Upload profile picture",
+                "summary": "Gets group word filters",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Profile picture",
-                        "name": "request",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.FiltersResponseItem"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -674,62 +763,133 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/profile_picture/{ident}": {
+        "/group/get/{id}": {
             "get": {
-                "description": "Gets a user's profile picture from their identifier",
+                "description": "Gets group information",
                 "produces": [
-                    "image/jpeg"
+                    "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Retrieves profile picture",
+                "summary": "Get group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User identifier",
-                        "name": "ident",
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.GetResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/user/register": {
+        "/group/history/{id}": {
+            "get": {
+                "description": "Gets top-level message history from a group in descending order, with thread reply counts. Messages by blocked users are left out. Without an anchor the newest messages are returned. At most one of before, after, or around may be given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets group messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of messages to retreive (\u003c= 100, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor; gets messages older than it",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor; gets messages newer than it",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID; gets messages surrounding and including it",
+                        "name": "around",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/invite": {
             "post": {
-                "description": "Registers a new user given the provided arguments",
+                "description": "Creates an invite code to an unlisted or private group, optionally expiring at a UTC time or after a number of uses. Any member may invite others to an unlisted group, but only moderators to a private one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "group"
                 ],
-                "summary": "Register a new user",
+                "summary": "Create invite",
                 "parameters": [
                     {
-                        "description": "User registration information",
+                        "description": "Group and limits of the invite",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.RegisterRequest"
+                            "$ref": "#/definitions/group.InviteRequest"
                         }
                     }
                 ],
@@ -737,93 +897,2983 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.RegisterResponse"
+                            "$ref": "#/definitions/group.InviteResponseItem"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/ws/{group}": {
-            "get": {
-                "description": "Opens a WebSocket for a user on a group",
+        "/group/invite/accept/{code}": {
+            "post": {
+                "description": "Joins the user to the group of an invite, responding with the group ID",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "ws"
+                    "group"
                 ],
-                "summary": "Opens a WebSocket",
+                "summary": "Accept invite",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Group ID",
-                        "name": "group",
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
                     "401": {
                         "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/invite/members/{id}": {
+            "get": {
+                "description": "Gets the current members of a group who joined through an invite, for moderators to trace a leaked link",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets members joined by invite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.InviteMembersResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/invite/revoke": {
+            "post": {
+                "description": "Stops an invite from being used. Its creator or a moderator may revoke it, and moderators may also remove the members who joined through it, which is announced to the group and closes their sockets on it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Revoke invite",
+                "parameters": [
+                    {
+                        "description": "Invite to revoke",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.RevokeInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.RevokeInviteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/invites/{id}": {
+            "get": {
+                "description": "Gets the invites to a group, newest first, with how many current members joined through each. Moderators see every invite, while other members only see their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets invites",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.InviteResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/kick": {
+            "post": {
+                "description": "Removes a member from a group and closes their sockets on it. They may rejoin. Moderators may only be kicked by the owner.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Kick group member",
+                "parameters": [
+                    {
+                        "description": "Member to kick",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.SanctionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/lift": {
+            "post": {
+                "description": "Lifts every mute or ban in effect on a user in a group. The kind is one of mute or ban.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Lift group sanction",
+                "parameters": [
+                    {
+                        "description": "Sanction to lift",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.LiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/log/{id}": {
+            "get": {
+                "description": "Gets the actions taken by the moderators of a group, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets moderation log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include entries before this entry ID",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.LogResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/members/{id}": {
+            "get": {
+                "description": "Gets the members of a group, owners first, then moderators, then everyone else, each by display name. Members who have blocked the user are left out, as are those whose privacy settings hide them from the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets group members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of members to retrieve (\u003c= 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.MembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/mute": {
+            "post": {
+                "description": "Prevents a member from posting to a group for the duration in seconds, or until lifted if zero",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Mute group member",
+                "parameters": [
+                    {
+                        "description": "Member to mute",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.SanctionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/pin": {
+            "post": {
+                "description": "Pins a message to its group",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Pin message",
+                "parameters": [
+                    {
+                        "description": "Message to pin",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.PinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/pins/{id}": {
+            "get": {
+                "description": "Gets the pinned messages of a group, most recently pinned first, leaving out those by blocked users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets pinned messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.PinsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/popular/{count}": {
+            "get": {
+                "description": "Gets the most popular groups by member count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets popular groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Count of groups to return",
+                        "name": "count",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.PopularResponseItem"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/posting": {
+            "post": {
+                "description": "Restricts who may post to a group and how often. Slow mode makes each user wait a number of seconds (\u003c= 21600) between messages, announcement-only lets only moderators post, and a number of days (\u003c= 365) may be required between joining and posting. Zero turns a restriction off. Moderators are exempt.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Set group posting policy",
+                "parameters": [
+                    {
+                        "description": "Posting policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.PostingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/react": {
+            "post": {
+                "description": "Adds a reaction to a message",
+                "tags": [
+                    "group"
+                ],
+                "summary": "React to message",
+                "parameters": [
+                    {
+                        "description": "Reaction to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.ReactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/report": {
+            "post": {
+                "description": "Reports a message to the moderators of its group. A user may only have one open report on a message.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Report message",
+                "parameters": [
+                    {
+                        "description": "Message to report",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.ReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/reports": {
+            "get": {
+                "description": "Gets the open reports in every group the user moderates, oldest first. Reports raised automatically by moderation rules have an empty reporter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets open reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include reports from this group",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of reports",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include reports after this report ID",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.ReportsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/reschedule": {
+            "post": {
+                "description": "Replaces the contents and timing of a message the user has waiting to be posted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Edit scheduled message",
+                "parameters": [
+                    {
+                        "description": "Updated message",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.RescheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.ScheduledResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/resolve": {
+            "post": {
+                "description": "Resolves a report along with every other open report on the same message. The action is one of dismiss, delete, warn, mute, or ban. Mutes and bans last for the duration in seconds, or until lifted if zero.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Resolve report",
+                "parameters": [
+                    {
+                        "description": "Report resolution",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.ResolveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/retention": {
+            "post": {
+                "description": "Sets how long the messages of a group are kept before being purged: forever, a number of days, or until the end of term. Keeping them as \"default\" follows the site-wide setting.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Set group retention",
+                "parameters": [
+                    {
+                        "description": "Retention policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.RetentionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/revisions/{message_id}": {
+            "get": {
+                "description": "Gets the previous contents of an edited message in descending order. Only the author and moderators of the group may see them, and they are gone once the message is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets message revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.RevisionsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/sanctions/{id}": {
+            "get": {
+                "description": "Gets the mutes and bans in effect in a group, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets group sanctions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.SanctionsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/schedule": {
+            "post": {
+                "description": "Schedules a message to be posted to a group at a future UTC time. Recurring messages repeat every given number of seconds (at least an hour), optionally until a UTC time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Schedule message",
+                "parameters": [
+                    {
+                        "description": "Message to schedule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.ScheduledResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/scheduled": {
+            "get": {
+                "description": "Gets the messages the user has waiting to be posted, soonest first. Moderators may instead get those of every member of a group, so as to cancel them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets scheduled messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include messages for this group",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include messages from every member of the group; requires group_id and moderator rights",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/group.ScheduledResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/search/{id}": {
+            "get": {
+                "description": "Searches the messages of a group using web search syntax: words, \"quoted phrases\", ` + "`" + `or` + "`" + `, and ` + "`" + `-` + "`" + ` to exclude. Messages by blocked users are left out. Snippets are HTML with each match wrapped in a mark element.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Searchs messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text to search messages for",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include messages from this user identifier",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include messages sent at or after this UTC time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include messages sent at or before this UTC time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order of results, either rank (default) or recent",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of messages to retreive (1 to 20)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous search to continue from",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/thread/{message_id}": {
+            "get": {
+                "description": "Gets replies to a message in ascending order, leaving out those by blocked users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Gets thread replies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Parent message ID",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of replies to retreive (1 to 20)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor; gets replies newer than it",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/group.ThreadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/unbookmark": {
+            "post": {
+                "description": "Removes the user's bookmark of a message",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Remove bookmark",
+                "parameters": [
+                    {
+                        "description": "Message to remove the bookmark of",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.UnbookmarkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/unfilter": {
+            "post": {
+                "description": "Removes a moderation rule from a group",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Remove group word filter",
+                "parameters": [
+                    {
+                        "description": "Rule to remove",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.UnfilterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/unpin": {
+            "post": {
+                "description": "Unpins a message from its group",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Unpin message",
+                "parameters": [
+                    {
+                        "description": "Message to unpin",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.PinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/unreact": {
+            "post": {
+                "description": "Removes a reaction from a message",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Remove reaction",
+                "parameters": [
+                    {
+                        "description": "Reaction to remove",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.ReactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/unschedule": {
+            "post": {
+                "description": "Cancels a message waiting to be posted. Moderators may cancel messages scheduled by anyone in their groups.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Cancel scheduled message",
+                "parameters": [
+                    {
+                        "description": "Message to cancel",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.UnscheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/group/vote": {
+            "post": {
+                "description": "Replaces the user's votes on a poll with the given options. Voting for no options retracts the vote.",
+                "tags": [
+                    "group"
+                ],
+                "summary": "Vote on poll",
+                "parameters": [
+                    {
+                        "description": "Poll and options to vote for",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/group.VoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/bio": {
+            "post": {
+                "description": "Updates a user's bio",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Updates bio",
+                "parameters": [
+                    {
+                        "description": "New bio",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.BioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/block": {
+            "post": {
+                "description": "Hides a user's messages from history, search, and live chat. Users may not block themselves.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Block user",
+                "parameters": [
+                    {
+                        "description": "User to block",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.BlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/blocked": {
+            "get": {
+                "description": "Returns all blocked users",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get blocked users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.BlockedResponseItem"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/bookmarks": {
+            "get": {
+                "description": "Returns the messages bookmarked by the user across all groups, most recently bookmarked first. Messages in private groups the user has since left are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get bookmarks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Max number of bookmarks to retreive (\u003c= 50, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page to continue from",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.BookmarksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/display_name": {
+            "post": {
+                "description": "Updates a user's display name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Updates display name",
+                "parameters": [
+                    {
+                        "description": "New display name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.DisplayNameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/email": {
+            "post": {
+                "description": "Updates a user's email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Updates email",
+                "parameters": [
+                    {
+                        "description": "New email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/get/{ident}": {
+            "get": {
+                "description": "Fetches publically available user information and groups, leaving out private groups and direct conversations. Users may limit their groups to those shared with the viewer, or hide them entirely.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Fetch user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User identifier",
+                        "name": "ident",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.GetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/groups": {
+            "get": {
+                "description": "Returns all groups a user belongs to along with their notification preferences",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.GroupsResponseItem"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/join": {
+            "post": {
+                "description": "Adds a user to a group, unless they are banned from it, and announces it to the group. Private groups may only be joined by invite, and direct conversations not at all.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Join group",
+                "parameters": [
+                    {
+                        "description": "Group to join",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.JoinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/leave": {
+            "post": {
+                "description": "Removes a user from a group and announces it to the group. Direct conversations may not be left.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Leave group",
+                "parameters": [
+                    {
+                        "description": "Group to leave",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.JoinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/mentions": {
+            "get": {
+                "description": "Returns messages mentioning the user in descending order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get mentions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Max number of mentions to retreive (\u003c= 20)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID cutoff; searches in reverse from this message (exclusive)",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.MentionsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/notifications": {
+            "get": {
+                "description": "Returns the notifications in the inbox of the user in descending order, along with how many are unread",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of notifications to retrieve (default 20, \u003c= 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Notification ID cutoff; searches in reverse from this notification (exclusive)",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.NotificationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/notifications/group": {
+            "post": {
+                "description": "Sets how much the user is notified of in a group they belong to. The level is one of all, mentions, or nothing, and the group may also be muted until a time. Users are always notified of moderator actions taken against them.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set group notification preference",
+                "parameters": [
+                    {
+                        "description": "Notification preference",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.GroupNotificationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/notifications/read": {
+            "post": {
+                "description": "Marks notifications in the inbox of the user as read. Notifications already read, or not belonging to the user, are ignored.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Mark notifications read",
+                "parameters": [
+                    {
+                        "description": "Notifications to mark read (\u003c= 100)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ReadNotificationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/notifications/read_all": {
+            "post": {
+                "description": "Marks every notification in the inbox of the user as read",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Mark all notifications read",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "post": {
+                "description": "Updates a user's password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Updates password",
+                "parameters": [
+                    {
+                        "description": "New password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.PasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/privacy": {
+            "post": {
+                "description": "Updates who may see which groups a user belongs to: everyone (public), only those sharing a group (members), or only moderators of each group (hidden)",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Updates privacy",
+                "parameters": [
+                    {
+                        "description": "New privacy setting",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.PrivacyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/profile_picture": {
+            "post": {
+                "description": "Uploads a new profile picture, replacing the old one",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Upload profile picture",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Profile picture",
+                        "name": "request",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/profile_picture/{ident}": {
+            "get": {
+                "description": "Gets a user's profile picture from their identifier",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Retrieves profile picture",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User identifier",
+                        "name": "ident",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "Registers a new user given the provided arguments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/unblock": {
+            "post": {
+                "description": "Shows a blocked user's messages again",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Unblock user",
+                "parameters": [
+                    {
+                        "description": "User to unblock",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.BlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/ws/{group}": {
+            "get": {
+                "description": "Opens a WebSocket for a user on a group",
+                "tags": [
+                    "ws"
+                ],
+                "summary": "Opens a WebSocket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "401": {
+                        "description": "Unauthorized"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.Message": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.Attachment"
+                    }
+                },
+                "contents": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "integer"
+                },
+                "edited_at": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "poll": {
+                    "$ref": "#/definitions/chat.PollState"
+                },
+                "system": {
+                    "$ref": "#/definitions/chat.SystemEvent"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "chat.Policy": {
+            "type": "object",
+            "properties": {
+                "announcement_only": {
+                    "description": "AnnouncementOnly lets only moderators post",
+                    "type": "boolean"
+                },
+                "member_days": {
+                    "description": "MemberDays is the number of days a user must have been a member to post",
+                    "type": "integer"
+                },
+                "slow_mode": {
+                    "description": "SlowMode is the number of seconds each user must wait between messages",
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.PollChoice": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "voted": {
+                    "type": "boolean"
+                },
+                "voters": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.PollState": {
+            "type": "object",
+            "properties": {
+                "anonymous": {
+                    "type": "boolean"
+                },
+                "closed_at": {
+                    "type": "integer"
+                },
+                "closes_at": {
+                    "type": "integer"
+                },
+                "multiple": {
+                    "type": "boolean"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.PollChoice"
+                    }
+                },
+                "voters": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.Reaction": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "reacted": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "chat.SystemEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "moderator_ident": {
+                    "description": "Moderator is the user who pinned a message, changed the description, or\ntook action against a member",
+                    "type": "string"
+                },
+                "user_ident": {
+                    "description": "Identifier is the user the event concerns, such as the member who joined",
+                    "type": "string"
+                }
+            }
+        },
+        "group.AllResponseItem": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.BookmarkRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.ClosePollRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.DeleteRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.DescribeRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.EditRequest": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.ExportJobResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.FilterRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "word": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.FiltersResponseItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "word": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.GetResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "member_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "posting": {
+                    "$ref": "#/definitions/chat.Policy"
+                },
+                "retention": {
+                    "$ref": "#/definitions/retention.Policy"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/group.HistoryResponseItem"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Next continues towards older messages, Prev towards newer ones",
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.HistoryResponseItem": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.Attachment"
+                    }
+                },
+                "contents": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "integer"
+                },
+                "edited_at": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_reply_iat": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "poll": {
+                    "$ref": "#/definitions/chat.PollState"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.Reaction"
+                    }
+                },
+                "reply_count": {
+                    "type": "integer"
+                },
+                "system": {
+                    "$ref": "#/definitions/chat.SystemEvent"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.InviteMembersResponseItem": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.InviteRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.InviteResponseItem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "integer"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.LiftRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.LogResponseItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "moderator_ident": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.MembersResponse": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/group.MembersResponseItem"
                     }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.MembersResponseItem": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "ident": {
+                    "type": "string"
+                },
+                "joined_at": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.PinRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.PinsResponseItem": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "pinned_at": {
+                    "type": "integer"
+                },
+                "pinned_by": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.PopularResponseItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.PostingRequest": {
+            "type": "object",
+            "properties": {
+                "announcement_only": {
+                    "type": "boolean"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "member_days": {
+                    "type": "integer"
+                },
+                "slow_mode": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.ReactRequest": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.ReportRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.ReportsResponseItem": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "type": "integer"
+                },
+                "reported_at": {
+                    "type": "integer"
+                },
+                "reporter_ident": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.RescheduleRequest": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "repeat_every": {
+                    "type": "integer"
+                },
+                "repeat_until": {
+                    "type": "integer"
+                },
+                "send_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.ResolveRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.RetentionRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "keep": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.RevisionsResponseItem": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "group.RevokeInviteRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "remove_members": {
+                    "description": "RemoveMembers also removes everyone who joined through the invite",
+                    "type": "boolean"
+                }
+            }
+        },
+        "group.RevokeInviteResponse": {
+            "type": "object",
+            "properties": {
+                "removed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.SanctionRequest": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Only used by mutes and bans",
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_ident": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.LoginRequest": {
+        },
+        "group.SanctionsResponseItem": {
             "type": "object",
             "properties": {
-                "email": {
+                "expires_at": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
                     "type": "string"
                 },
-                "password": {
+                "moderator_ident": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_ident": {
                     "type": "string"
                 }
             }
         },
-        "auth.LoginResponse": {
+        "group.ScheduleRequest": {
             "type": "object",
             "properties": {
-                "ident": {
+                "contents": {
                     "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "repeat_every": {
+                    "type": "integer"
+                },
+                "repeat_until": {
+                    "type": "integer"
+                },
+                "send_at": {
+                    "type": "integer"
                 }
             }
         },
-        "group.AllResponseItem": {
+        "group.ScheduledResponseItem": {
             "type": "object",
             "properties": {
-                "description": {
+                "contents": {
                     "type": "string"
                 },
+                "group_id": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "repeat_every": {
+                    "type": "integer"
+                },
+                "repeat_until": {
+                    "type": "integer"
+                },
+                "send_at": {
+                    "type": "integer"
+                },
+                "user_ident": {
                     "type": "string"
                 }
             }
         },
-        "group.GetResponse": {
+        "group.SearchResponse": {
             "type": "object",
             "properties": {
-                "description": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/group.SearchResponseItem"
+                    }
                 }
             }
         },
-        "group.HistoryResponseItem": {
+        "group.SearchResponseItem": {
             "type": "object",
             "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.Attachment"
+                    }
+                },
                 "contents": {
                     "type": "string"
                 },
@@ -833,35 +3883,130 @@ const docTemplate = `{
                 "message_id": {
                     "type": "integer"
                 },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chat.Reaction"
+                    }
+                },
+                "snippet": {
+                    "type": "string"
+                },
                 "user_ident": {
                     "type": "string"
                 }
             }
         },
-        "group.PopularResponseItem": {
+        "group.ThreadResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/group.HistoryResponseItem"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Next continues towards newer replies",
+                    "type": "string"
+                }
+            }
+        },
+        "group.UnbookmarkRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.UnfilterRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "group.UnscheduleRequest": {
             "type": "object",
             "properties": {
                 "id": {
                     "type": "integer"
+                }
+            }
+        },
+        "group.VoteRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "group.SearchResponseItem": {
+        "inbox.Item": {
             "type": "object",
             "properties": {
-                "contents": {
+                "actor_ident": {
+                    "type": "string"
+                },
+                "body": {
                     "type": "string"
                 },
+                "group_id": {
+                    "type": "integer"
+                },
                 "iat": {
                     "type": "integer"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
                 "message_id": {
                     "type": "integer"
                 },
-                "user_ident": {
+                "read_at": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "member.Preference": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string"
+                },
+                "muted_until": {
+                    "description": "MutedUntil silences the group entirely until the time, whatever the level",
+                    "type": "integer"
+                }
+            }
+        },
+        "retention.Policy": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "keep": {
                     "type": "string"
                 }
             }
@@ -882,6 +4027,69 @@ const docTemplate = `{
                 }
             }
         },
+        "user.BlockedResponseItem": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.BookmarksResponse": {
+            "type": "object",
+            "properties": {
+                "bookmarks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.BookmarksResponseItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.BookmarksResponseItem": {
+            "type": "object",
+            "properties": {
+                "bookmarked_at": {
+                    "type": "integer"
+                },
+                "contents": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
         "user.DisplayNameRequest": {
             "type": "object",
             "properties": {
@@ -932,14 +4140,39 @@ const docTemplate = `{
                 }
             }
         },
+        "user.GroupNotificationsRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "muted_until": {
+                    "description": "MutedUntil silences the group until the time, or unmutes it if omitted",
+                    "type": "integer"
+                }
+            }
+        },
         "user.GroupsResponseItem": {
             "type": "object",
             "properties": {
                 "group_id": {
                     "type": "integer"
                 },
+                "kind": {
+                    "type": "string"
+                },
+                "muted": {
+                    "description": "Muted is whether the group is silenced now, by its level or until a time",
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
+                },
+                "notifications": {
+                    "$ref": "#/definitions/member.Preference"
                 }
             }
         },
@@ -951,6 +4184,43 @@ const docTemplate = `{
                 }
             }
         },
+        "user.MentionsResponseItem": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "user_ident": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.NotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/inbox.Item"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
         "user.PasswordRequest": {
             "type": "object",
             "properties": {
@@ -962,6 +4232,25 @@ const docTemplate = `{
                 }
             }
         },
+        "user.PrivacyRequest": {
+            "type": "object",
+            "properties": {
+                "privacy": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.ReadNotificationsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "user.RegisterRequest": {
             "type": "object",
             "properties": {
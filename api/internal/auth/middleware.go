@@ -31,3 +31,15 @@ func ExpectToken(c *gin.Context) *Token {
 		return token
 	}
 }
+
+// OptionalToken gets the token of a request on a public route, returning nil
+// if the request is not authenticated.
+func OptionalToken(c *gin.Context) *Token {
+	if raw, err := c.Cookie("token"); err != nil {
+		return nil
+	} else if token, err := ParseToken(raw); err != nil {
+		return nil
+	} else {
+		return token
+	}
+}
@@ -0,0 +1,311 @@
+package chat
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// MaxAttachmentSize caps the size of a single uploaded file in bytes.
+const MaxAttachmentSize = 8 << 20
+
+// MaxAttachments caps the number of files attached to a single message.
+const MaxAttachments = 4
+
+const maxAttachmentNameLength = 256
+
+// UploadExpiry is how long in seconds an upload may wait to be attached to a
+// message before it is discarded.
+const UploadExpiry = 24 * 60 * 60
+
+// AttachmentTypes are the content types accepted for upload, as detected from
+// the file contents rather than trusted from the client.
+var AttachmentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+var (
+	ErrAttachmentSize = errors.New("attachment too large")
+	ErrAttachmentType = errors.New("attachment type not allowed")
+	ErrAttachment     = errors.New("invalid attachment")
+	ErrNotMember      = errors.New("not a member of group")
+)
+
+// Attachment describes a file attached to a message.
+type Attachment struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func newAttachment(x model.MessageAttachment) Attachment {
+	return Attachment{x.ID, x.Name, x.ContentType, x.Size}
+}
+
+// AttachmentPath gets the location on disk of a file from the hash of its
+// contents.
+func AttachmentPath(hash string) string {
+	return filepath.Join(globals.Opts.AttachmentFolderPath, hash[:2], hash)
+}
+
+func detectType(file multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	t, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return t, nil
+}
+
+// store writes a file to disk under the hash of its contents, returning the
+// hash. Files already stored are replaced by the identical upload, restoring
+// them should they be removed as unreferenced before the upload is recorded.
+func store(file multipart.File) (string, error) {
+	if err := os.MkdirAll(globals.Opts.AttachmentFolderPath, 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(globals.Opts.AttachmentFolderPath, "upload-*")
+	if err != nil {
+		return "", err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sh := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, sh), io.LimitReader(file, MaxAttachmentSize)); err != nil {
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	hash := fmt.Sprintf("%x", sh.Sum(nil))
+	path := AttachmentPath(hash)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	return hash, os.Rename(tmp.Name(), path)
+}
+
+// Upload stores a file for a member of a group. The attachment is not visible
+// until it is linked to a message by Post, and is discarded if it is not linked
+// within UploadExpiry.
+func Upload(user model.UserAccount, group int64, header *multipart.FileHeader) (Attachment, error) {
+	if header.Size > MaxAttachmentSize {
+		return Attachment{}, ErrAttachmentSize
+	}
+
+	if role, err := member.Role(user.ID, group); err != nil {
+		return Attachment{}, err
+	} else if role == "" {
+		return Attachment{}, ErrNotMember
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	defer file.Close()
+
+	t, err := detectType(file)
+	if err != nil {
+		return Attachment{}, err
+	} else if !lo.Contains(AttachmentTypes, t) {
+		return Attachment{}, ErrAttachmentType
+	}
+
+	hash, err := store(file)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	name := filepath.Base(header.Filename)
+	if len(name) > maxAttachmentNameLength {
+		name = name[len(name)-maxAttachmentNameLength:]
+	}
+
+	var dest model.MessageAttachment
+	stmt := MessageAttachment.INSERT(
+		MessageAttachment.RoomID,
+		MessageAttachment.UserID,
+		MessageAttachment.Hash,
+		MessageAttachment.Name,
+		MessageAttachment.ContentType,
+		MessageAttachment.Size,
+		MessageAttachment.Iat,
+	).MODEL(model.MessageAttachment{
+		RoomID:      group,
+		UserID:      user.ID,
+		Hash:        hash,
+		Name:        name,
+		ContentType: t,
+		Size:        header.Size,
+		Iat:         time.Now().Unix(),
+	}).RETURNING(MessageAttachment.AllColumns)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil {
+		return Attachment{}, err
+	}
+
+	return newAttachment(dest), nil
+}
+
+// link attaches a user's pending uploads in a group to a newly posted message.
+func link(tx qrm.DB, msg model.RoomMessage, ids []int64) ([]Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var dest []model.MessageAttachment
+	stmt := MessageAttachment.UPDATE(MessageAttachment.MessageID).SET(Int64(msg.ID)).WHERE(
+		MessageAttachment.ID.IN(lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })...).
+			AND(MessageAttachment.UserID.EQ(Int64(*msg.UserID))).
+			AND(MessageAttachment.RoomID.EQ(Int64(msg.RoomID))).
+			AND(MessageAttachment.MessageID.IS_NULL()),
+	).RETURNING(MessageAttachment.AllColumns)
+
+	if err := stmt.Query(tx, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	} else if len(dest) != len(ids) {
+		return nil, ErrAttachment
+	}
+
+	return lo.Map(dest, func(x model.MessageAttachment, _ int) Attachment { return newAttachment(x) }), nil
+}
+
+// RemoveUnreferenced deletes the stored files of those hashes that no attachment
+// refers to any longer.
+func RemoveUnreferenced(hashes []string) error {
+	hashes = lo.Uniq(hashes)
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	var dest []model.MessageAttachment
+	stmt := SELECT(MessageAttachment.Hash).DISTINCT().FROM(MessageAttachment).WHERE(
+		MessageAttachment.Hash.IN(lo.Map(hashes, func(x string, _ int) Expression { return String(x) })...),
+	)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return err
+	}
+
+	referenced := lo.Map(dest, func(x model.MessageAttachment, _ int) string { return x.Hash })
+	for _, hash := range lo.Without(hashes, referenced...) {
+		if err := os.Remove(AttachmentPath(hash)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExpireUploads discards uploads made before a time that were never attached
+// to a message, returning how many were discarded.
+func ExpireUploads(before int64) (int, error) {
+	var dest []model.MessageAttachment
+	stmt := MessageAttachment.DELETE().WHERE(
+		MessageAttachment.MessageID.IS_NULL().AND(MessageAttachment.Iat.LT(Int64(before))),
+	).RETURNING(MessageAttachment.ID, MessageAttachment.Hash)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return 0, err
+	}
+
+	return len(dest), RemoveUnreferenced(lo.Map(dest, func(x model.MessageAttachment, _ int) string { return x.Hash }))
+}
+
+// Attachments gets the files attached to each of the given messages that have
+// not been deleted.
+func Attachments(ids []int64) (map[int64][]Attachment, error) {
+	if len(ids) == 0 {
+		return map[int64][]Attachment{}, nil
+	}
+
+	var dest []model.MessageAttachment
+	stmt := SELECT(MessageAttachment.AllColumns).FROM(
+		MessageAttachment.INNER_JOIN(RoomMessage, MessageAttachment.MessageID.EQ(RoomMessage.ID)),
+	).WHERE(
+		MessageAttachment.MessageID.IN(lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })...).
+			AND(RoomMessage.DeletedAt.IS_NULL()),
+	).ORDER_BY(MessageAttachment.ID.ASC())
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	attachments := make(map[int64][]Attachment)
+	for _, x := range dest {
+		attachments[*x.MessageID] = append(attachments[*x.MessageID], newAttachment(x))
+	}
+
+	return attachments, nil
+}
+
+// Download gets an attachment for a user, ensuring they are a member of the
+// group it was uploaded to and that its message has not been deleted.
+func Download(user model.UserAccount, id int64) (model.MessageAttachment, error) {
+	var dest model.MessageAttachment
+	stmt := SELECT(MessageAttachment.AllColumns).FROM(MessageAttachment).WHERE(MessageAttachment.ID.EQ(Int64(id)))
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return model.MessageAttachment{}, ErrNotFound
+	} else if err != nil {
+		return model.MessageAttachment{}, err
+	}
+
+	if role, err := member.Role(user.ID, dest.RoomID); err != nil {
+		return model.MessageAttachment{}, err
+	} else if role == "" {
+		return model.MessageAttachment{}, ErrNotMember
+	}
+
+	if dest.MessageID == nil {
+		// Pending uploads are only visible to the uploader
+		if dest.UserID != user.ID {
+			return model.MessageAttachment{}, ErrNotFound
+		}
+	} else if _, err := messageGroup(*dest.MessageID); err != nil {
+		return model.MessageAttachment{}, err
+	}
+
+	return dest, nil
+}
@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+const MaxNoteLength = 256
+
+var ErrNote = errors.New("invalid bookmark note")
+
+// Bookmark saves a message for a user with an optional note. Bookmarking a
+// message again replaces its note. Users may only bookmark messages they can
+// see.
+func Bookmark(user model.UserAccount, id int64, note string) error {
+	note = strings.TrimSpace(note)
+	if len(note) > MaxNoteLength {
+		return ErrNote
+	}
+
+	if _, err := viewableGroup(user.ID, id); err != nil {
+		return err
+	}
+
+	stmt := MessageBookmark.INSERT(
+		MessageBookmark.MessageID,
+		MessageBookmark.UserID,
+		MessageBookmark.Note,
+		MessageBookmark.Iat,
+	).MODEL(model.MessageBookmark{
+		MessageID: id,
+		UserID:    user.ID,
+		Note:      lo.EmptyableToPtr(note),
+		Iat:       time.Now().Unix(),
+	}).ON_CONFLICT(MessageBookmark.UserID, MessageBookmark.MessageID).DO_UPDATE(
+		SET(MessageBookmark.Note.SET(MessageBookmark.EXCLUDED.Note)),
+	)
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// Unbookmark removes a user's bookmark of a message.
+func Unbookmark(user model.UserAccount, id int64) error {
+	stmt := MessageBookmark.DELETE().WHERE(
+		MessageBookmark.MessageID.EQ(Int64(id)).AND(MessageBookmark.UserID.EQ(Int64(user.ID))),
+	)
+
+	if res, err := stmt.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// unbookmarkDeleted removes every bookmark of a message being deleted.
+func unbookmarkDeleted(tx qrm.DB, id int64) error {
+	_, err := MessageBookmark.DELETE().WHERE(MessageBookmark.MessageID.EQ(Int64(id))).Exec(tx)
+	return err
+}
@@ -0,0 +1,448 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+const MaxContentsLength = 512
+
+// Kinds of message.
+const (
+	KindText   = "text"
+	KindPoll   = "poll"
+	KindSystem = "system"
+)
+
+const (
+	EventMessageCreated = "message.created"
+	EventMessageEdited  = "message.edited"
+	EventMessageDeleted = "message.deleted"
+)
+
+var (
+	ErrNotFound  = errors.New("message not found")
+	ErrForbidden = errors.New("not permitted to modify message")
+	ErrInvalid   = errors.New("invalid message contents")
+	ErrParent    = errors.New("invalid thread parent")
+	ErrMuted     = errors.New("muted in group")
+)
+
+// Message is the event broadcast to a group whenever one of its messages is
+// created, edited, or deleted. System messages have no author.
+type Message struct {
+	Type       string `json:"type"`
+	ID         int64  `json:"message_id"`
+	GroupID    int64  `json:"group_id"`
+	Identifier string `json:"user_ident,omitempty"`
+	Kind       string `json:"kind"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+	EditedAt   *int64 `json:"edited_at,omitempty"`
+	DeletedAt  *int64 `json:"deleted_at,omitempty"`
+	ParentID   *int64 `json:"parent_id,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Poll        *PollState   `json:"poll,omitempty"`
+	System      *SystemEvent `json:"system,omitempty"`
+}
+
+func validate(contents string) error {
+	if len(contents) == 0 || len(contents) > MaxContentsLength {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+func newMessage(t string, msg model.RoomMessage, user model.UserAccount) Message {
+	return Message{
+		t,
+		msg.ID,
+		msg.RoomID,
+		user.Identifier,
+		msg.Kind,
+		msg.Contents,
+		msg.Iat,
+		msg.EditedAt,
+		msg.DeletedAt,
+		msg.ParentID,
+		nil,
+		nil,
+		SystemOf(msg),
+	}
+}
+
+// checkParent ensures a thread parent is a top-level message of the same group
+// that is not a system message.
+func checkParent(group int64, parent int64) error {
+	var dest model.RoomMessage
+	stmt := SELECT(RoomMessage.ID).FROM(RoomMessage).WHERE(
+		RoomMessage.ID.EQ(Int64(parent)).
+			AND(RoomMessage.RoomID.EQ(Int64(group))).
+			AND(RoomMessage.ParentID.IS_NULL()).
+			AND(RoomMessage.DeletedAt.IS_NULL()).
+			AND(RoomMessage.Kind.NOT_EQ(String(KindSystem))),
+	)
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return ErrParent
+	} else {
+		return err
+	}
+}
+
+// checkDirect ensures a user may post to a group if it is a direct
+// conversation, in which only participants may post and not while any of them
+// has blocked another.
+func checkDirect(user model.UserAccount, group int64) error {
+	if kind, err := member.Kind(group); err != nil || kind != member.KindDirect {
+		return err
+	}
+
+	participants, err := member.Members(group)
+	if err != nil {
+		return err
+	} else if !lo.Contains(participants, user.ID) {
+		return ErrNotMember
+	}
+
+	if blocked, err := member.Blocked(participants...); err != nil {
+		return err
+	} else if blocked {
+		return member.ErrBlocked
+	}
+
+	return nil
+}
+
+// flag records content written by a user that matched flagged words, reporting
+// the message to the moderators of its group.
+func flag(user model.UserAccount, group int64, id int64, contents string, words []string) {
+	if err := moderation.Flag(user.ID, &group, &id, moderation.FieldMessage, contents, words); err != nil {
+		fmt.Printf("[chat] Failed to flag message: %s\n", err.Error())
+	}
+
+	if err := Report(nil, id, "Matched flagged words: "+strings.Join(words, ", ")); err != nil && err != ErrReported {
+		fmt.Printf("[chat] Failed to report message: %s\n", err.Error())
+	}
+}
+
+// Draft is a message to be posted.
+type Draft struct {
+	Contents string
+
+	// ParentID is the message whose thread the draft replies to, if any
+	ParentID *int64
+
+	// Attachments are the IDs of files uploaded by the author to attach
+	Attachments []int64
+
+	// Poll makes the draft a poll, asking its contents as the question
+	Poll *PollDraft
+}
+
+// Post moderates and persists a new message from a user, then broadcasts it to
+// those in the group who have not blocked them. The origin subscriber, if any,
+// does not receive the broadcast.
+// Members mentioned in the contents are notified on every socket they have open.
+// Users muted or banned from the group may not post, nor may anyone to a
+// direct conversation in which a participant has blocked another. Users held
+// back by the posting policy of the group are given a Restriction.
+func Post(user model.UserAccount, group int64, draft Draft, origin *hub.Subscriber) (Message, error) {
+	draft.Attachments = lo.Uniq(draft.Attachments)
+
+	if sanction, err := member.Active(user.ID, group, member.SanctionMute, member.SanctionBan); err != nil {
+		return Message{}, err
+	} else if sanction != nil {
+		return Message{}, ErrMuted
+	}
+
+	if err := checkDirect(user, group); err != nil {
+		return Message{}, err
+	}
+
+	if len(draft.Attachments) > MaxAttachments {
+		return Message{}, ErrAttachment
+	} else if len(draft.Attachments) == 0 || len(draft.Contents) > 0 {
+		// Messages carrying attachments may be sent without any text
+		if err := validate(draft.Contents); err != nil {
+			return Message{}, err
+		}
+	}
+
+	if draft.ParentID != nil {
+		if err := checkParent(group, *draft.ParentID); err != nil {
+			return Message{}, err
+		}
+	}
+
+	kind := KindText
+	if draft.Poll != nil {
+		kind = KindPoll
+		if err := draft.Poll.validate(group, draft.Contents); err != nil {
+			return Message{}, err
+		}
+	}
+
+	result, err := moderation.Check(&group, draft.Contents)
+	if err != nil {
+		return Message{}, err
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return Message{}, err
+	}
+
+	defer tx.Rollback()
+
+	if err := checkPolicy(tx, user, group); err != nil {
+		return Message{}, err
+	}
+
+	var dest model.RoomMessage
+	stmt := RoomMessage.INSERT(
+		RoomMessage.UserID,
+		RoomMessage.RoomID,
+		RoomMessage.Kind,
+		RoomMessage.Contents,
+		RoomMessage.Iat,
+		RoomMessage.ParentID,
+	).MODEL(model.RoomMessage{
+		UserID:   &user.ID,
+		RoomID:   group,
+		Kind:     kind,
+		Contents: result.Contents,
+		Iat:      time.Now().Unix(),
+		ParentID: draft.ParentID,
+	}).RETURNING(RoomMessage.AllColumns)
+
+	if err := stmt.Query(tx, &dest); err != nil {
+		return Message{}, err
+	}
+
+	attachments, err := link(tx, dest, draft.Attachments)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var poll *PollState
+	if draft.Poll != nil {
+		if p, err := createPoll(tx, dest, *draft.Poll); err != nil {
+			return Message{}, err
+		} else {
+			poll = &p
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Message{}, err
+	}
+
+	msg := newMessage(EventMessageCreated, dest, user)
+	msg.Attachments = attachments
+	msg.Poll = poll
+	hub.PublishFrom(group, user.ID, msg, origin)
+
+	if len(result.Flagged) > 0 {
+		flag(user, group, dest.ID, draft.Contents, result.Flagged)
+	}
+
+	mentioned, err := mention(msg, user)
+	if err != nil {
+		fmt.Printf("[chat] Failed to deliver mentions: %s\n", err.Error())
+	}
+
+	if err := notifyPost(msg, user, mentioned); err != nil {
+		fmt.Printf("[chat] Failed to deliver notifications: %s\n", err.Error())
+	}
+
+	return msg, nil
+}
+
+// modify locks a message for update by a user, recording its current contents
+// as a revision before the update is applied.
+func modify(tx qrm.DB, user model.UserAccount, id int64) (model.RoomMessage, model.UserAccount, error) {
+	var dest model.RoomMessage
+	stmt := SELECT(RoomMessage.AllColumns).FROM(RoomMessage).WHERE(
+		RoomMessage.ID.EQ(Int64(id)).AND(RoomMessage.DeletedAt.IS_NULL()),
+	).FOR(UPDATE())
+
+	if err := stmt.Query(tx, &dest); err == qrm.ErrNoRows {
+		return model.RoomMessage{}, model.UserAccount{}, ErrNotFound
+	} else if err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	// System messages have no author, so only moderators may modify them. Authors
+	// who have been muted, banned, or have left may not change what they posted.
+	if role, err := member.Role(user.ID, dest.RoomID); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	} else if role != member.RoleModerator && role != member.RoleOwner {
+		if dest.UserID == nil || *dest.UserID != user.ID {
+			return model.RoomMessage{}, model.UserAccount{}, ErrForbidden
+		} else if role == "" {
+			return model.RoomMessage{}, model.UserAccount{}, ErrNotMember
+		}
+
+		if sanction, err := member.Active(user.ID, dest.RoomID, member.SanctionMute, member.SanctionBan); err != nil {
+			return model.RoomMessage{}, model.UserAccount{}, err
+		} else if sanction != nil {
+			return model.RoomMessage{}, model.UserAccount{}, ErrMuted
+		}
+	}
+
+	var author model.UserAccount
+
+	if dest.UserID != nil {
+		if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.ID.EQ(Int64(*dest.UserID))).Query(tx, &author); err != nil {
+			return model.RoomMessage{}, model.UserAccount{}, err
+		}
+	}
+
+	ins := RoomMessageRevision.INSERT(
+		RoomMessageRevision.MessageID,
+		RoomMessageRevision.UserID,
+		RoomMessageRevision.Contents,
+		RoomMessageRevision.Iat,
+	).MODEL(model.RoomMessageRevision{
+		MessageID: dest.ID,
+		UserID:    user.ID,
+		Contents:  dest.Contents,
+		Iat:       time.Now().Unix(),
+	})
+
+	if _, err := ins.Exec(tx); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	return dest, author, nil
+}
+
+// Edit replaces the contents of a message. Only the author or a moderator of
+// the group may edit a message, and the question of a poll may not be changed.
+// Authors who are muted, banned, or no longer members may not edit.
+func Edit(user model.UserAccount, id int64, contents string) (Message, error) {
+	if err := validate(contents); err != nil {
+		return Message{}, err
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return Message{}, err
+	}
+
+	defer tx.Rollback()
+
+	msg, author, err := modify(tx, user, id)
+	if err != nil {
+		return Message{}, err
+	} else if msg.Kind != KindText {
+		return Message{}, ErrForbidden
+	}
+
+	result, err := moderation.Check(&msg.RoomID, contents)
+	if err != nil {
+		return Message{}, err
+	}
+
+	now := time.Now().Unix()
+	msg.Contents = result.Contents
+	msg.EditedAt = &now
+
+	stmt := RoomMessage.UPDATE(RoomMessage.Contents, RoomMessage.EditedAt).
+		MODEL(msg).
+		WHERE(RoomMessage.ID.EQ(Int64(id)))
+
+	if _, err := stmt.Exec(tx); err != nil {
+		return Message{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Message{}, err
+	}
+
+	if len(result.Flagged) > 0 {
+		flag(user, msg.RoomID, msg.ID, contents, result.Flagged)
+	}
+
+	event := newMessage(EventMessageEdited, msg, author)
+	hub.PublishFrom(msg.RoomID, lo.FromPtr(msg.UserID), event, nil)
+	return event, nil
+}
+
+// tombstone deletes a message on behalf of a user as part of a transaction,
+// returning the deleted message and its author.
+func tombstone(tx qrm.DB, user model.UserAccount, id int64) (model.RoomMessage, model.UserAccount, error) {
+	msg, author, err := modify(tx, user, id)
+	if err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	now := time.Now().Unix()
+	msg.Contents = ""
+	msg.DeletedAt = &now
+
+	stmt := RoomMessage.UPDATE(RoomMessage.Contents, RoomMessage.DeletedAt).
+		MODEL(msg).
+		WHERE(RoomMessage.ID.EQ(Int64(id)))
+
+	if _, err := stmt.Exec(tx); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	if err := unpinDeleted(tx, id); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	if err := unbookmarkDeleted(tx, id); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	if err := inbox.Withdraw(tx, id); err != nil {
+		return model.RoomMessage{}, model.UserAccount{}, err
+	}
+
+	return msg, author, nil
+}
+
+// Delete tombstones a message, clearing its contents and removing its pin and
+// any bookmarks of it. The previous contents are kept as a revision. Only the
+// author or a moderator of the group may delete a message, and only while the
+// author is a member who is neither muted nor banned.
+func Delete(user model.UserAccount, id int64) (Message, error) {
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return Message{}, err
+	}
+
+	defer tx.Rollback()
+
+	msg, author, err := tombstone(tx, user, id)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Message{}, err
+	}
+
+	event := newMessage(EventMessageDeleted, msg, author)
+	hub.PublishFrom(msg.RoomID, lo.FromPtr(msg.UserID), event, nil)
+	return event, nil
+}
@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"regexp"
+	"strings"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	mentionEveryone   = "everyone"
+	mentionModerators = "moderators"
+)
+
+// MaxMentions caps the number of distinct handles resolved in a single message.
+const MaxMentions = 20
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w-]+)`)
+
+// parseMentions extracts the distinct handles mentioned in a message.
+func parseMentions(contents string) []string {
+	handles := lo.Uniq(lo.Map(mentionPattern.FindAllStringSubmatch(contents, -1), func(x []string, _ int) string {
+		return x[1]
+	}))
+
+	if len(handles) > MaxMentions {
+		return handles[:MaxMentions]
+	}
+
+	return handles
+}
+
+// resolveMentions finds the members of a group mentioned by a set of handles.
+// A handle matches a member by identifier or, case-insensitively, by display
+// name. Only moderators may mention everyone. The author is never considered
+// mentioned, nor are members who have blocked them.
+func resolveMentions(group int64, author int64, handles []string) ([]model.UserAccount, error) {
+	var cond BoolExpression = Bool(false)
+	var names []Expression
+
+	for _, handle := range handles {
+		switch strings.ToLower(handle) {
+		case mentionEveryone:
+			if ok, err := member.IsModerator(author, group); err != nil {
+				return nil, err
+			} else if ok {
+				cond = cond.OR(Bool(true))
+			}
+		case mentionModerators:
+			cond = cond.OR(member.Moderates())
+		default:
+			names = append(names, String(strings.ToLower(handle)))
+			cond = cond.OR(UserAccount.Identifier.EQ(String(handle)))
+		}
+	}
+
+	if len(names) > 0 {
+		cond = cond.OR(LOWER(UserAccount.DisplayName).IN(names...))
+	}
+
+	stmt := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(
+		UserRoom.INNER_JOIN(UserAccount, UserRoom.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		UserRoom.RoomID.EQ(Int64(group)).
+			AND(UserAccount.ID.NOT_EQ(Int64(author))).
+			AND(member.NotBlocking(UserAccount.ID, author)).
+			AND(cond),
+	)
+
+	var dest []model.UserAccount
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// mention records the mentions in a newly posted message, returning the users
+// mentioned so that they may be notified.
+func mention(msg Message, author model.UserAccount) ([]model.UserAccount, error) {
+	handles := parseMentions(msg.Contents)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	users, err := resolveMentions(msg.GroupID, author.ID, handles)
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+
+	stmt := MessageMention.INSERT(MessageMention.MessageID, MessageMention.UserID).
+		MODELS(lo.Map(users, func(x model.UserAccount, _ int) model.MessageMention {
+			return model.MessageMention{MessageID: msg.ID, UserID: x.ID}
+		})).
+		ON_CONFLICT().DO_NOTHING()
+
+	// Mentions are still recorded for members who have silenced the group
+	if _, err := stmt.Exec(globals.Database); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
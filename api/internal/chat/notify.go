@@ -0,0 +1,100 @@
+package chat
+
+import (
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// replyRecipient gets the author of the thread a message replies to if they
+// should be told of the reply, or zero otherwise.
+func replyRecipient(msg Message, author int64) (int64, error) {
+	if msg.ParentID == nil {
+		return 0, nil
+	}
+
+	var parent model.RoomMessage
+	if err := SELECT(RoomMessage.UserID).FROM(RoomMessage).WHERE(RoomMessage.ID.EQ(Int64(*msg.ParentID))).Query(globals.Database, &parent); err == qrm.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if parent.UserID == nil || *parent.UserID == author {
+		return 0, nil
+	}
+
+	// The author of the thread may have since left a group they can no longer read
+	if ok, err := member.CanView(*parent.UserID, msg.GroupID); err != nil || !ok {
+		return 0, err
+	}
+
+	if blocked, err := member.Blocked(author, *parent.UserID); err != nil || blocked {
+		return 0, err
+	}
+
+	return *parent.UserID, nil
+}
+
+// notifyPost delivers the notifications raised by a newly posted message to the
+// members it mentions, the author of the thread it replies to, and the other
+// participants of a direct conversation. Nobody is notified more than once.
+func notifyPost(msg Message, author model.UserAccount, mentioned []model.UserAccount) error {
+	notice := func(user int64, kind string) inbox.Notice {
+		return inbox.Notice{
+			UserID:    user,
+			Kind:      kind,
+			GroupID:   &msg.GroupID,
+			MessageID: &msg.ID,
+			Actor:     &author,
+			Body:      msg.Contents,
+		}
+	}
+
+	seen := map[int64]bool{author.ID: true}
+	notices := lo.Map(mentioned, func(x model.UserAccount, _ int) inbox.Notice {
+		seen[x.ID] = true
+		return notice(x.ID, inbox.KindMention)
+	})
+
+	if user, err := replyRecipient(msg, author.ID); err != nil {
+		return err
+	} else if user != 0 && !seen[user] {
+		seen[user] = true
+		notices = append(notices, notice(user, inbox.KindReply))
+	}
+
+	if kind, err := member.Kind(msg.GroupID); err != nil {
+		return err
+	} else if kind == member.KindDirect {
+		participants, err := member.Members(msg.GroupID)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range participants {
+			if !seen[user] {
+				notices = append(notices, notice(user, inbox.KindDirect))
+			}
+		}
+	}
+
+	return inbox.Send(notices...)
+}
+
+// moderationNotice tells a user of an action taken against them by the
+// moderators of a group, who are not named.
+func moderationNotice(user int64, group int64, action string, reason string) inbox.Notice {
+	body := action
+	if reason != "" {
+		body += ": " + reason
+	}
+
+	return inbox.Notice{UserID: user, Kind: inbox.KindModeration, GroupID: &group, Body: body}
+}
@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// MaxPins caps the number of messages pinned in a single group.
+const MaxPins = 25
+
+const (
+	EventMessagePinned   = "message.pinned"
+	EventMessageUnpinned = "message.unpinned"
+)
+
+var ErrPinLimit = errors.New("too many pinned messages")
+
+// PinEvent is broadcast to a group whenever one of its messages is pinned or
+// unpinned.
+type PinEvent struct {
+	Type       string `json:"type"`
+	ID         int64  `json:"message_id"`
+	GroupID    int64  `json:"group_id"`
+	Identifier string `json:"user_ident"`
+	IssuedAt   int64  `json:"iat"`
+}
+
+// moderate gets the group of a message, ensuring the user moderates it.
+func moderate(user model.UserAccount, id int64) (int64, error) {
+	group, err := messageGroup(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok, err := member.IsModerator(user.ID, group); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, ErrForbidden
+	}
+
+	return group, nil
+}
+
+// Pin pins a message to its group, announcing it with a system message. Only
+// moderators of the group may pin messages, and pinning an already pinned
+// message has no effect.
+func Pin(user model.UserAccount, id int64) error {
+	group, err := moderate(user, id)
+	if err != nil {
+		return err
+	}
+
+	var count struct {
+		Count int64 `alias:"count"`
+	}
+
+	stmt := SELECT(COUNT(MessagePin.MessageID).AS("count")).FROM(MessagePin).WHERE(MessagePin.RoomID.EQ(Int64(group)))
+
+	if err := stmt.Query(globals.Database, &count); err != nil {
+		return err
+	} else if count.Count >= MaxPins {
+		return ErrPinLimit
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	ins := MessagePin.INSERT(
+		MessagePin.MessageID,
+		MessagePin.RoomID,
+		MessagePin.UserID,
+		MessagePin.Iat,
+	).MODEL(model.MessagePin{
+		MessageID: id,
+		RoomID:    group,
+		UserID:    user.ID,
+		Iat:       now,
+	}).ON_CONFLICT().DO_NOTHING()
+
+	if res, err := ins.Exec(tx); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	msg, err := Announce(tx, group, SystemEvent{Event: SystemPinned, Moderator: user.Identifier, MessageID: &id})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	hub.Publish(group, PinEvent{EventMessagePinned, id, group, user.Identifier, now}, nil)
+	hub.Publish(group, msg, nil)
+	return nil
+}
+
+// Unpin unpins a message from its group. Only moderators of the group may
+// unpin messages.
+func Unpin(user model.UserAccount, id int64) error {
+	group, err := moderate(user, id)
+	if err != nil {
+		return err
+	}
+
+	if res, err := MessagePin.DELETE().WHERE(MessagePin.MessageID.EQ(Int64(id))).Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n > 0 {
+		hub.Publish(group, PinEvent{EventMessageUnpinned, id, group, user.Identifier, time.Now().Unix()}, nil)
+	}
+
+	return nil
+}
+
+// unpinDeleted removes the pin of a message being deleted.
+func unpinDeleted(tx qrm.DB, id int64) error {
+	_, err := MessagePin.DELETE().WHERE(MessagePin.MessageID.EQ(Int64(id))).Exec(tx)
+	return err
+}
@@ -0,0 +1,142 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// Limits of the posting policy of a group.
+const (
+	MaxSlowMode   = 6 * 60 * 60
+	MaxMemberDays = 365
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+var ErrPolicy = errors.New("invalid posting policy")
+
+// Policy restricts who may post to a group and how often. Moderators are not
+// bound by it.
+type Policy struct {
+	// SlowMode is the number of seconds each user must wait between messages
+	SlowMode int64 `json:"slow_mode"`
+
+	// AnnouncementOnly lets only moderators post
+	AnnouncementOnly bool `json:"announcement_only"`
+
+	// MemberDays is the number of days a user must have been a member to post
+	MemberDays int64 `json:"member_days"`
+}
+
+// Restriction is the error returned when a policy prevents a user from posting.
+type Restriction struct {
+	Reason string
+
+	// RetryAt is when the user may next post, or nil if waiting will not help
+	RetryAt *int64
+}
+
+func (r *Restriction) Error() string {
+	return r.Reason
+}
+
+// PolicyOf gets the posting policy of a group.
+func PolicyOf(group model.Room) Policy {
+	return Policy{group.SlowMode, group.Announcement, group.MemberDays}
+}
+
+// SetPolicy changes the posting policy of a group.
+func SetPolicy(group int64, policy Policy) error {
+	if policy.SlowMode < 0 || policy.SlowMode > MaxSlowMode || policy.MemberDays < 0 || policy.MemberDays > MaxMemberDays {
+		return ErrPolicy
+	}
+
+	stmt := Room.UPDATE(Room.SlowMode, Room.Announcement, Room.MemberDays).
+		MODEL(model.Room{SlowMode: policy.SlowMode, Announcement: policy.AnnouncementOnly, MemberDays: policy.MemberDays}).
+		WHERE(Room.ID.EQ(Int64(group)))
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// checkPolicy ensures the posting policy of a group lets a user post now, as
+// part of the transaction inserting their message.
+func checkPolicy(tx qrm.DB, user model.UserAccount, group int64) error {
+	var room model.Room
+	if err := SELECT(Room.SlowMode, Room.Announcement, Room.MemberDays).FROM(Room).WHERE(Room.ID.EQ(Int64(group))).Query(tx, &room); err == qrm.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	policy := PolicyOf(room)
+	if policy == (Policy{}) {
+		return nil
+	}
+
+	var membership model.UserRoom
+	stmt := SELECT(UserRoom.Role, UserRoom.JoinedAt).FROM(UserRoom).WHERE(
+		UserRoom.UserID.EQ(Int64(user.ID)).AND(UserRoom.RoomID.EQ(Int64(group))),
+	)
+
+	if err := stmt.Query(tx, &membership); err != nil && err != qrm.ErrNoRows {
+		return err
+	} else if membership.Role == member.RoleModerator || membership.Role == member.RoleOwner {
+		return nil
+	}
+
+	now := time.Now().Unix()
+
+	if policy.AnnouncementOnly {
+		return &Restriction{"only moderators may post", nil}
+	}
+
+	if policy.MemberDays > 0 {
+		if membership.Role == "" {
+			return &Restriction{"only members may post", nil}
+		}
+
+		if at := membership.JoinedAt + policy.MemberDays*secondsPerDay; at > now {
+			return &Restriction{fmt.Sprintf("members may post after %d days", policy.MemberDays), &at}
+		}
+	}
+
+	if policy.SlowMode > 0 {
+		// Posts by the same user wait on each other so that they cannot both pass.
+		// Users may post to public groups without being members, so it is their
+		// account that is locked rather than their membership.
+		var locked model.UserAccount
+		if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.ID.EQ(Int64(user.ID))).FOR(UPDATE()).Query(tx, &locked); err != nil {
+			return err
+		}
+
+		var last struct {
+			Iat *int64 `alias:"last"`
+		}
+
+		stmt := SELECT(MAX(RoomMessage.Iat).AS("last")).FROM(RoomMessage).WHERE(
+			RoomMessage.RoomID.EQ(Int64(group)).AND(RoomMessage.UserID.EQ(Int64(user.ID))),
+		)
+
+		if err := stmt.Query(tx, &last); err != nil && err != qrm.ErrNoRows {
+			return err
+		}
+
+		if last.Iat != nil {
+			if at := *last.Iat + policy.SlowMode; at > now {
+				return &Restriction{fmt.Sprintf("slow mode allows one message every %d seconds", policy.SlowMode), &at}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+const (
+	MinPollOptions        = 2
+	MaxPollOptions        = 10
+	MaxPollOptionLength   = 128
+	maxPollQuestionLength = 256
+)
+
+const EventPollUpdated = "poll.updated"
+
+var (
+	ErrPoll       = errors.New("invalid poll")
+	ErrPollClosed = errors.New("poll is closed")
+	ErrVote       = errors.New("invalid vote")
+)
+
+// PollDraft is a poll to be posted, with the question as the message contents.
+type PollDraft struct {
+	Options   []string `json:"options"`
+	Multiple  bool     `json:"multiple"`
+	Anonymous bool     `json:"anonymous"`
+	ClosesAt  *int64   `json:"closes_at"`
+}
+
+// PollState is the current state of a poll.
+type PollState struct {
+	Multiple  bool         `json:"multiple"`
+	Anonymous bool         `json:"anonymous"`
+	ClosesAt  *int64       `json:"closes_at,omitempty"`
+	ClosedAt  *int64       `json:"closed_at,omitempty"`
+	Voters    int64        `json:"voters"`
+	Options   []PollChoice `json:"options"`
+}
+
+// PollChoice is the tally of one option of a poll. Voters are only listed for
+// polls that are not anonymous.
+type PollChoice struct {
+	ID     int64    `json:"id"`
+	Label  string   `json:"label"`
+	Votes  int64    `json:"votes"`
+	Voted  bool     `json:"voted,omitempty"`
+	Voters []string `json:"voters,omitempty"`
+}
+
+// PollEvent is broadcast to a group whenever the votes on or state of one of
+// its polls change.
+type PollEvent struct {
+	Type    string    `json:"type"`
+	ID      int64     `json:"message_id"`
+	GroupID int64     `json:"group_id"`
+	Poll    PollState `json:"poll"`
+}
+
+func (p PollState) closed(now int64) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && *p.ClosesAt <= now)
+}
+
+// validate checks a poll draft, masking its options under the rules of a
+// group.
+func (d *PollDraft) validate(group int64, question string) error {
+	if len(question) > maxPollQuestionLength {
+		return ErrPoll
+	}
+
+	if len(d.Options) < MinPollOptions || len(d.Options) > MaxPollOptions {
+		return ErrPoll
+	}
+
+	if d.ClosesAt != nil && *d.ClosesAt <= time.Now().Unix() {
+		return ErrPoll
+	}
+
+	for i, option := range d.Options {
+		option = strings.TrimSpace(option)
+		if len(option) == 0 || len(option) > MaxPollOptionLength {
+			return ErrPoll
+		}
+
+		result, err := moderation.Check(&group, option)
+		if err != nil {
+			return err
+		}
+
+		d.Options[i] = result.Contents
+	}
+
+	if len(lo.Uniq(d.Options)) != len(d.Options) {
+		return ErrPoll
+	}
+
+	return nil
+}
+
+// createPoll stores the poll of a newly posted message.
+func createPoll(tx qrm.DB, msg model.RoomMessage, draft PollDraft) (PollState, error) {
+	ins := Poll.INSERT(Poll.MessageID, Poll.Multiple, Poll.Anonymous, Poll.ClosesAt).MODEL(model.Poll{
+		MessageID: msg.ID,
+		Multiple:  draft.Multiple,
+		Anonymous: draft.Anonymous,
+		ClosesAt:  draft.ClosesAt,
+	})
+
+	if _, err := ins.Exec(tx); err != nil {
+		return PollState{}, err
+	}
+
+	var options []model.PollOption
+	stmt := PollOption.INSERT(PollOption.MessageID, PollOption.Label).MODELS(lo.Map(draft.Options, func(x string, _ int) model.PollOption {
+		return model.PollOption{MessageID: msg.ID, Label: x}
+	})).RETURNING(PollOption.AllColumns)
+
+	if err := stmt.Query(tx, &options); err != nil {
+		return PollState{}, err
+	}
+
+	return PollState{
+		Multiple:  draft.Multiple,
+		Anonymous: draft.Anonymous,
+		ClosesAt:  draft.ClosesAt,
+		Options: lo.Map(options, func(x model.PollOption, _ int) PollChoice {
+			return PollChoice{ID: x.ID, Label: x.Label}
+		}),
+	}, nil
+}
+
+// Polls tallies the polls of each of the given messages that have not been
+// deleted, marking the options voted for by user. A user of zero matches no
+// votes.
+func Polls(ids []int64, user int64) (map[int64]PollState, error) {
+	polls := make(map[int64]PollState)
+	if len(ids) == 0 {
+		return polls, nil
+	}
+
+	in := lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })
+
+	var dest []struct {
+		model.Poll
+
+		Options []model.PollOption
+	}
+
+	stmt := SELECT(Poll.AllColumns, PollOption.AllColumns).FROM(
+		Poll.
+			INNER_JOIN(RoomMessage, Poll.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(PollOption, PollOption.MessageID.EQ(Poll.MessageID)),
+	).WHERE(
+		Poll.MessageID.IN(in...).AND(RoomMessage.DeletedAt.IS_NULL()),
+	).ORDER_BY(Poll.MessageID.ASC(), PollOption.ID.ASC())
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	} else if len(dest) == 0 {
+		return polls, nil
+	}
+
+	var votes []struct {
+		model.PollVote
+
+		User model.UserAccount
+	}
+
+	vstmt := SELECT(PollVote.AllColumns, UserAccount.ID, UserAccount.Identifier).FROM(
+		PollVote.INNER_JOIN(UserAccount, PollVote.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		PollVote.MessageID.IN(in...),
+	).ORDER_BY(PollVote.Iat.ASC())
+
+	if err := vstmt.Query(globals.Database, &votes); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	voters := make(map[int64]map[int64]bool)
+	byOption := make(map[int64][]string)
+	voted := make(map[int64]bool)
+
+	for _, x := range votes {
+		if voters[x.MessageID] == nil {
+			voters[x.MessageID] = make(map[int64]bool)
+		}
+
+		voters[x.MessageID][x.UserID] = true
+		byOption[x.OptionID] = append(byOption[x.OptionID], x.User.Identifier)
+		voted[x.OptionID] = voted[x.OptionID] || x.UserID == user
+	}
+
+	for _, x := range dest {
+		poll := PollState{
+			Multiple:  x.Multiple,
+			Anonymous: x.Anonymous,
+			ClosesAt:  x.ClosesAt,
+			ClosedAt:  x.ClosedAt,
+			Voters:    int64(len(voters[x.MessageID])),
+			Options: lo.Map(x.Options, func(o model.PollOption, _ int) PollChoice {
+				option := PollChoice{ID: o.ID, Label: o.Label, Votes: int64(len(byOption[o.ID])), Voted: voted[o.ID]}
+				if !x.Anonymous {
+					option.Voters = byOption[o.ID]
+				}
+
+				return option
+			}),
+		}
+
+		polls[x.MessageID] = poll
+	}
+
+	return polls, nil
+}
+
+// publishPoll broadcasts the current tally of a poll to its group.
+func publishPoll(group int64, id int64) error {
+	polls, err := Polls([]int64{id}, 0)
+	if err != nil {
+		return err
+	}
+
+	hub.Publish(group, PollEvent{EventPollUpdated, id, group, polls[id]}, nil)
+	return nil
+}
+
+// pollOf gets the poll of a message that has not been deleted, along with its
+// group.
+func pollOf(id int64) (PollState, int64, error) {
+	group, err := messageGroup(id)
+	if err != nil {
+		return PollState{}, 0, err
+	}
+
+	polls, err := Polls([]int64{id}, 0)
+	if err != nil {
+		return PollState{}, 0, err
+	}
+
+	poll, ok := polls[id]
+	if !ok {
+		return PollState{}, 0, ErrNotFound
+	}
+
+	return poll, group, nil
+}
+
+// Vote replaces the votes of a member on a poll. Voting for no options
+// retracts their vote.
+func Vote(user model.UserAccount, id int64, options []int64) error {
+	poll, group, err := pollOf(id)
+	if err != nil {
+		return err
+	}
+
+	if poll.closed(time.Now().Unix()) {
+		return ErrPollClosed
+	}
+
+	if role, err := member.Role(user.ID, group); err != nil {
+		return err
+	} else if role == "" {
+		return ErrNotMember
+	}
+
+	options = lo.Uniq(options)
+	valid := lo.Map(poll.Options, func(x PollChoice, _ int) int64 { return x.ID })
+
+	if (!poll.Multiple && len(options) > 1) || !lo.Every(valid, options) {
+		return ErrVote
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	del := PollVote.DELETE().WHERE(PollVote.MessageID.EQ(Int64(id)).AND(PollVote.UserID.EQ(Int64(user.ID))))
+	if _, err := del.Exec(tx); err != nil {
+		return err
+	}
+
+	if len(options) > 0 {
+		now := time.Now().Unix()
+		ins := PollVote.INSERT(PollVote.OptionID, PollVote.MessageID, PollVote.UserID, PollVote.Iat).MODELS(lo.Map(options, func(x int64, _ int) model.PollVote {
+			return model.PollVote{OptionID: x, MessageID: id, UserID: user.ID, Iat: now}
+		}))
+
+		if _, err := ins.Exec(tx); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return publishPoll(group, id)
+}
+
+// ClosePoll stops a poll from accepting votes. Only the author or a moderator
+// of the group may close a poll.
+func ClosePoll(user model.UserAccount, id int64) error {
+	var msg model.RoomMessage
+	stmt := SELECT(RoomMessage.ID, RoomMessage.RoomID, RoomMessage.UserID).FROM(
+		RoomMessage.INNER_JOIN(Poll, Poll.MessageID.EQ(RoomMessage.ID)),
+	).WHERE(
+		RoomMessage.ID.EQ(Int64(id)).AND(RoomMessage.DeletedAt.IS_NULL()),
+	)
+
+	if err := stmt.Query(globals.Database, &msg); err == qrm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if lo.FromPtr(msg.UserID) != user.ID {
+		if ok, err := member.IsModerator(user.ID, msg.RoomID); err != nil {
+			return err
+		} else if !ok {
+			return ErrForbidden
+		}
+	}
+
+	upd := Poll.UPDATE(Poll.ClosedAt).SET(Int64(time.Now().Unix())).WHERE(
+		Poll.MessageID.EQ(Int64(id)).AND(Poll.ClosedAt.IS_NULL()),
+	)
+
+	if res, err := upd.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPollClosed
+	}
+
+	return publishPoll(msg.RoomID, id)
+}
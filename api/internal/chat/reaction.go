@@ -0,0 +1,180 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const MaxEmojiLength = 32
+
+const (
+	EventReactionAdded   = "reaction.added"
+	EventReactionRemoved = "reaction.removed"
+)
+
+var ErrEmoji = errors.New("invalid emoji")
+
+// ReactionEvent is broadcast to a group whenever a reaction is added to or
+// removed from one of its messages.
+type ReactionEvent struct {
+	Type       string `json:"type"`
+	ID         int64  `json:"message_id"`
+	GroupID    int64  `json:"group_id"`
+	Identifier string `json:"user_ident"`
+	Emoji      string `json:"emoji"`
+}
+
+// Reaction is the aggregate of every user's reaction to a message with a
+// single emoji.
+type Reaction struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+func validateEmoji(emoji string) error {
+	if len(emoji) == 0 || len(emoji) > MaxEmojiLength || !utf8.ValidString(emoji) || strings.ContainsAny(emoji, " \t\r\n") {
+		return ErrEmoji
+	}
+
+	return nil
+}
+
+// messageGroup gets the group of a message that has not been deleted.
+func messageGroup(id int64) (int64, error) {
+	var dest model.RoomMessage
+	stmt := SELECT(RoomMessage.RoomID).FROM(RoomMessage).WHERE(
+		RoomMessage.ID.EQ(Int64(id)).AND(RoomMessage.DeletedAt.IS_NULL()),
+	)
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, err
+	} else {
+		return dest.RoomID, nil
+	}
+}
+
+// viewableGroup gets the group of a message that has not been deleted, as long
+// as a user may read it. Messages the user may not read are not found, so as not
+// to reveal that they exist.
+func viewableGroup(user int64, id int64) (int64, error) {
+	group, err := messageGroup(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok, err := member.CanView(user, group); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, ErrNotFound
+	}
+
+	return group, nil
+}
+
+// React adds a user's reaction to a message. Reacting twice with the same
+// emoji has no effect. The reaction is not sent to those who have blocked the
+// user.
+func React(user model.UserAccount, id int64, emoji string) error {
+	if err := validateEmoji(emoji); err != nil {
+		return err
+	}
+
+	group, err := viewableGroup(user.ID, id)
+	if err != nil {
+		return err
+	}
+
+	stmt := MessageReaction.INSERT(
+		MessageReaction.MessageID,
+		MessageReaction.UserID,
+		MessageReaction.Emoji,
+		MessageReaction.Iat,
+	).MODEL(model.MessageReaction{
+		MessageID: id,
+		UserID:    user.ID,
+		Emoji:     emoji,
+		Iat:       time.Now().Unix(),
+	}).ON_CONFLICT().DO_NOTHING()
+
+	if res, err := stmt.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n > 0 {
+		hub.PublishFrom(group, user.ID, ReactionEvent{EventReactionAdded, id, group, user.Identifier, emoji}, nil)
+	}
+
+	return nil
+}
+
+// Unreact removes a user's reaction from a message.
+func Unreact(user model.UserAccount, id int64, emoji string) error {
+	group, err := viewableGroup(user.ID, id)
+	if err != nil {
+		return err
+	}
+
+	stmt := MessageReaction.DELETE().WHERE(
+		MessageReaction.MessageID.EQ(Int64(id)).
+			AND(MessageReaction.UserID.EQ(Int64(user.ID))).
+			AND(MessageReaction.Emoji.EQ(String(emoji))),
+	)
+
+	if res, err := stmt.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n > 0 {
+		hub.PublishFrom(group, user.ID, ReactionEvent{EventReactionRemoved, id, group, user.Identifier, emoji}, nil)
+	}
+
+	return nil
+}
+
+// Reactions aggregates the reactions on each of the given messages, marking
+// those made by user. A user of zero matches no reactions.
+func Reactions(ids []int64, user int64) (map[int64][]Reaction, error) {
+	if len(ids) == 0 {
+		return map[int64][]Reaction{}, nil
+	}
+
+	stmt := SELECT(
+		MessageReaction.MessageID.AS("message_id"),
+		MessageReaction.Emoji.AS("emoji"),
+		COUNT(MessageReaction.UserID).AS("count"),
+		BOOL_OR(MessageReaction.UserID.EQ(Int64(user))).AS("reacted"),
+	).FROM(MessageReaction).WHERE(
+		MessageReaction.MessageID.IN(lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })...),
+	).GROUP_BY(
+		MessageReaction.MessageID, MessageReaction.Emoji,
+	).ORDER_BY(MIN(MessageReaction.Iat).ASC())
+
+	var dest []struct {
+		MessageID int64  `alias:"message_id"`
+		Emoji     string `alias:"emoji"`
+		Count     int64  `alias:"count"`
+		Reacted   bool   `alias:"reacted"`
+	}
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	reactions := make(map[int64][]Reaction)
+	for _, x := range dest {
+		reactions[x.MessageID] = append(reactions[x.MessageID], Reaction{x.Emoji, x.Count, x.Reacted})
+	}
+
+	return reactions, nil
+}
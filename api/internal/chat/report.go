@@ -0,0 +1,273 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+const MaxReasonLength = 512
+
+// Actions a moderator may take to resolve a report.
+const (
+	ResolveDismiss = "dismiss"
+	ResolveDelete  = "delete"
+	ResolveWarn    = "warn"
+	ResolveMute    = "mute"
+	ResolveBan     = "ban"
+)
+
+var ResolveActions = []string{ResolveDismiss, ResolveDelete, ResolveWarn, ResolveMute, ResolveBan}
+
+const EventModeration = "moderation.action"
+
+var (
+	ErrReason   = errors.New("invalid reason")
+	ErrReported = errors.New("message already reported")
+	ErrAction   = errors.New("invalid moderation action")
+)
+
+// ModerationEvent is sent to a user on every socket they have open whenever a
+// moderator takes action against them.
+type ModerationEvent struct {
+	Type      string `json:"type"`
+	GroupID   int64  `json:"group_id"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// Report files a report on a message for review by the moderators of its
+// group. The reporter is nil for reports raised by moderation rules. A user may
+// only have one open report on a message, and so may moderation rules.
+func Report(reporter *int64, id int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if len(reason) == 0 || len(reason) > MaxReasonLength {
+		return ErrReason
+	}
+
+	var msg model.RoomMessage
+	stmt := SELECT(RoomMessage.ID, RoomMessage.RoomID, RoomMessage.UserID).FROM(RoomMessage).WHERE(
+		RoomMessage.ID.EQ(Int64(id)).
+			AND(RoomMessage.DeletedAt.IS_NULL()).
+			AND(RoomMessage.UserID.IS_NOT_NULL()),
+	)
+
+	if err := stmt.Query(globals.Database, &msg); err == qrm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if reporter != nil {
+		if ok, err := member.CanView(*reporter, msg.RoomID); err != nil {
+			return err
+		} else if !ok {
+			return ErrNotFound
+		}
+
+		if *reporter == *msg.UserID {
+			return ErrForbidden
+		}
+	}
+
+	ins := MessageReport.INSERT(
+		MessageReport.MessageID,
+		MessageReport.RoomID,
+		MessageReport.UserID,
+		MessageReport.Reason,
+		MessageReport.Iat,
+	).MODEL(model.MessageReport{
+		MessageID: msg.ID,
+		RoomID:    msg.RoomID,
+		UserID:    reporter,
+		Reason:    reason,
+		Iat:       time.Now().Unix(),
+	}).ON_CONFLICT().DO_NOTHING()
+
+	if res, err := ins.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReported
+	}
+
+	return nil
+}
+
+// Resolution is the decision of a moderator on a report.
+type Resolution struct {
+	Action string
+	Reason string
+
+	// Duration of a mute or ban in seconds, lasting until lifted if zero
+	Duration int64
+}
+
+// Resolve acts on a report and closes it along with every other open report
+// on the same message. The decision is recorded in the moderation log of the
+// group, and the author is told of any action taken against them. Mutes and
+// bans are also announced to the group.
+func Resolve(moderator model.UserAccount, id int64, resolution Resolution) error {
+	resolution.Reason = strings.TrimSpace(resolution.Reason)
+	if !lo.Contains(ResolveActions, resolution.Action) || resolution.Duration < 0 {
+		return ErrAction
+	} else if len(resolution.Reason) > MaxReasonLength {
+		return ErrReason
+	}
+
+	var report struct {
+		model.MessageReport
+
+		Author model.UserAccount
+	}
+
+	stmt := SELECT(
+		MessageReport.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+	).FROM(
+		MessageReport.
+			INNER_JOIN(RoomMessage, MessageReport.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		MessageReport.ID.EQ(Int64(id)).AND(MessageReport.ResolvedAt.IS_NULL()),
+	)
+
+	if err := stmt.Query(globals.Database, &report); err == qrm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if ok, err := member.IsModerator(moderator.ID, report.RoomID); err != nil {
+		return err
+	} else if !ok {
+		return ErrForbidden
+	}
+
+	// Sanctions through reports follow the same hierarchy as those taken directly
+	if resolution.Action == ResolveMute || resolution.Action == ResolveBan {
+		if err := mayAct(moderator.ID, report.Author.ID, report.RoomID); err != nil {
+			return err
+		}
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var deleted *Message
+	if resolution.Action == ResolveDelete {
+		// The author may have deleted the message since it was reported
+		if msg, author, err := tombstone(tx, moderator, report.MessageID); err == nil {
+			deleted = lo.ToPtr(newMessage(EventMessageDeleted, msg, author))
+		} else if err != ErrNotFound {
+			return err
+		}
+	}
+
+	var expires *int64
+	var announcement *Message
+	if resolution.Action == ResolveMute || resolution.Action == ResolveBan {
+		if resolution.Duration > 0 {
+			expires = lo.ToPtr(time.Now().Unix() + resolution.Duration)
+		}
+
+		kind := lo.Ternary(resolution.Action == ResolveMute, member.SanctionMute, member.SanctionBan)
+		if _, err := member.Sanction(tx, model.RoomSanction{
+			RoomID:      report.RoomID,
+			UserID:      report.Author.ID,
+			ModeratorID: moderator.ID,
+			Kind:        kind,
+			Reason:      resolution.Reason,
+			ExpiresAt:   expires,
+		}); err != nil {
+			return err
+		}
+
+		msg, err := Announce(tx, report.RoomID, SystemEvent{
+			Event:      SystemModeration,
+			Identifier: report.Author.Identifier,
+			Moderator:  moderator.Identifier,
+			Action:     resolution.Action,
+			ExpiresAt:  expires,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		announcement = &msg
+	}
+
+	entry, err := moderation.Record(tx, model.ModerationLog{
+		RoomID:      report.RoomID,
+		ModeratorID: moderator.ID,
+		UserID:      report.Author.ID,
+		MessageID:   &report.MessageID,
+		Action:      resolution.Action,
+		Reason:      resolution.Reason,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	upd := MessageReport.UPDATE(MessageReport.ResolvedAt, MessageReport.LogID).SET(
+		Int64(entry.Iat), Int64(entry.ID),
+	).WHERE(
+		MessageReport.MessageID.EQ(Int64(report.MessageID)).AND(MessageReport.ResolvedAt.IS_NULL()),
+	)
+
+	if _, err := upd.Exec(tx); err != nil {
+		return err
+	}
+
+	// Authors are not told of dismissed reports or deleted messages
+	told := resolution.Action != ResolveDismiss && resolution.Action != ResolveDelete
+
+	var items []inbox.Item
+	if told {
+		if items, err = inbox.Deliver(tx, moderationNotice(report.Author.ID, report.RoomID, resolution.Action, resolution.Reason)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if told {
+		hub.Notify(report.Author.ID, ModerationEvent{EventModeration, report.RoomID, resolution.Action, resolution.Reason, expires})
+	}
+
+	inbox.Push(items)
+
+	if deleted != nil {
+		hub.PublishFrom(report.RoomID, report.Author.ID, *deleted, nil)
+	}
+
+	if announcement != nil {
+		hub.Publish(report.RoomID, *announcement, nil)
+	}
+
+	if resolution.Action == ResolveBan {
+		hub.Disconnect(report.Author.ID, report.RoomID)
+	}
+
+	return nil
+}
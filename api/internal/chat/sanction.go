@@ -0,0 +1,162 @@
+package chat
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+// Actions a moderator may take directly against a member of a group.
+const (
+	ActionKick   = "kick"
+	ActionMute   = ResolveMute
+	ActionBan    = ResolveBan
+	ActionUnmute = "unmute"
+	ActionUnban  = "unban"
+)
+
+var Actions = []string{ActionKick, ActionMute, ActionBan, ActionUnmute, ActionUnban}
+
+// Measure is an action taken by a moderator against a member of a group.
+type Measure struct {
+	Action string
+	Reason string
+
+	// Duration of a mute or ban in seconds, lasting until lifted if zero
+	Duration int64
+}
+
+// mayAct checks that a moderator may act against a user in a group. Nobody may
+// act against themselves or the owner, and only the owner may act against
+// other moderators.
+func mayAct(moderator int64, user int64, group int64) error {
+	role, err := member.Role(moderator, group)
+	if err != nil {
+		return err
+	} else if role != member.RoleModerator && role != member.RoleOwner {
+		return ErrForbidden
+	}
+
+	target, err := member.Role(user, group)
+	if err != nil {
+		return err
+	}
+
+	if user == moderator || target == member.RoleOwner || (target == member.RoleModerator && role != member.RoleOwner) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// Act takes a measure against a user in a group. Kicked users are removed from
+// the group but may rejoin, while banned users may not rejoin until the ban
+// expires or is lifted, and muted users may not post. Kicked and banned users
+// have their sockets on the group closed. The measure is recorded in the
+// moderation log of the group and announced to it, and the user is told of it.
+func Act(moderator model.UserAccount, group int64, user model.UserAccount, measure Measure) error {
+	measure.Reason = strings.TrimSpace(measure.Reason)
+	if !lo.Contains(Actions, measure.Action) || measure.Duration < 0 {
+		return ErrAction
+	} else if len(measure.Reason) > MaxReasonLength {
+		return ErrReason
+	}
+
+	if err := mayAct(moderator.ID, user.ID, group); err != nil {
+		return err
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var expires *int64
+	switch measure.Action {
+	case ActionKick:
+		del := UserRoom.DELETE().WHERE(
+			UserRoom.UserID.EQ(Int64(user.ID)).AND(UserRoom.RoomID.EQ(Int64(group))),
+		)
+
+		if res, err := del.Exec(tx); err != nil {
+			return err
+		} else if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotMember
+		}
+	case ActionMute, ActionBan:
+		if measure.Duration > 0 {
+			expires = lo.ToPtr(time.Now().Unix() + measure.Duration)
+		}
+
+		// Bans also remove the user from the group as part of the transaction
+		if _, err := member.Sanction(tx, model.RoomSanction{
+			RoomID:      group,
+			UserID:      user.ID,
+			ModeratorID: moderator.ID,
+			Kind:        measure.Action,
+			Reason:      measure.Reason,
+			ExpiresAt:   expires,
+		}); err != nil {
+			return err
+		}
+	case ActionUnmute, ActionUnban:
+		kind := lo.Ternary(measure.Action == ActionUnmute, member.SanctionMute, member.SanctionBan)
+		if n, err := member.Lift(tx, user.ID, group, kind); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+	}
+
+	if _, err := moderation.Record(tx, model.ModerationLog{
+		RoomID:      group,
+		ModeratorID: moderator.ID,
+		UserID:      user.ID,
+		Action:      measure.Action,
+		Reason:      measure.Reason,
+	}); err != nil {
+		return err
+	}
+
+	msg, err := Announce(tx, group, SystemEvent{
+		Event:      SystemModeration,
+		Identifier: user.Identifier,
+		Moderator:  moderator.Identifier,
+		Action:     measure.Action,
+		ExpiresAt:  expires,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	items, err := inbox.Deliver(tx, moderationNotice(user.ID, group, measure.Action, measure.Reason))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	hub.Publish(group, msg, nil)
+	inbox.Push(items)
+	hub.Notify(user.ID, ModerationEvent{EventModeration, group, measure.Action, measure.Reason, expires})
+	if measure.Action == ActionKick || measure.Action == ActionBan {
+		hub.Disconnect(user.ID, group)
+	}
+
+	return nil
+}
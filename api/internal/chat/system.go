@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+)
+
+// Events described by system messages.
+const (
+	SystemJoined      = "member.joined"
+	SystemLeft        = "member.left"
+	SystemPinned      = "message.pinned"
+	SystemDescription = "group.description"
+	SystemModeration  = "moderation.action"
+)
+
+// SystemEvent is the payload of a system message, which has no author.
+type SystemEvent struct {
+	Event string `json:"event"`
+
+	// Identifier is the user the event concerns, such as the member who joined
+	Identifier string `json:"user_ident,omitempty"`
+
+	// Moderator is the user who pinned a message, changed the description, or
+	// took action against a member
+	Moderator string `json:"moderator_ident,omitempty"`
+
+	MessageID   *int64  `json:"message_id,omitempty"`
+	Action      string  `json:"action,omitempty"`
+	Description *string `json:"description,omitempty"`
+	ExpiresAt   *int64  `json:"expires_at,omitempty"`
+}
+
+// Announce records a system message describing an event in a group as part of
+// a transaction. The message is returned to be published to the group once the
+// transaction has been committed.
+func Announce(tx qrm.DB, group int64, event SystemEvent) (Message, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var dest model.RoomMessage
+	stmt := RoomMessage.INSERT(
+		RoomMessage.RoomID,
+		RoomMessage.Kind,
+		RoomMessage.Contents,
+		RoomMessage.Payload,
+		RoomMessage.Iat,
+	).MODEL(model.RoomMessage{
+		RoomID:   group,
+		Kind:     KindSystem,
+		Contents: "",
+		Payload:  lo.ToPtr(string(payload)),
+		Iat:      time.Now().Unix(),
+	}).RETURNING(RoomMessage.AllColumns)
+
+	if err := stmt.Query(tx, &dest); err != nil {
+		return Message{}, err
+	}
+
+	return newMessage(EventMessageCreated, dest, model.UserAccount{}), nil
+}
+
+// SystemOf decodes the payload of a system message, returning nil for any other
+// kind of message.
+func SystemOf(msg model.RoomMessage) *SystemEvent {
+	if msg.Kind != KindSystem || msg.Payload == nil {
+		return nil
+	}
+
+	var event SystemEvent
+	if err := json.Unmarshal([]byte(*msg.Payload), &event); err != nil {
+		return nil
+	}
+
+	return &event
+}
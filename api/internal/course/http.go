@@ -13,6 +13,7 @@ import (
 	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
 	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
 	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
 )
 
 type DepartmentRequest struct {
@@ -82,7 +83,7 @@ func Group(g *gin.Context) {
 	label := fmt.Sprintf("%s %s", strings.ToUpper(uri.Department), strings.ToUpper(uri.Code))
 
 	var dest model.Room
-	stmt := SELECT(Room.ID).FROM(Room).WHERE(Room.Name.EQ(String(label)))
+	stmt := SELECT(Room.ID).FROM(Room).WHERE(Room.Name.EQ(String(label)).AND(Room.Kind.EQ(String(member.KindCourse))))
 
 	switch err := stmt.Query(globals.Database, &dest); err {
 	default:
@@ -0,0 +1,94 @@
+package dm
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// MaxParticipants caps the size of a direct conversation, including the user
+// who opens it.
+const MaxParticipants = 8
+
+var ErrParticipants = errors.New("invalid participants")
+
+// key identifies the direct conversation between exactly a set of users.
+func key(users []int64) string {
+	sorted := append([]int64(nil), users...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return strings.Join(lo.Map(sorted, func(x int64, _ int) string { return strconv.FormatInt(x, 10) }), ",")
+}
+
+// Conversation gets the direct conversation between a user and others, creating it if
+// it does not yet exist. Conversations may not be opened while any participant
+// has blocked another.
+func Conversation(user int64, others []int64) (int64, error) {
+	others = lo.Uniq(others)
+	if len(others) == 0 || len(others)+1 > MaxParticipants || lo.Contains(others, user) {
+		return 0, ErrParticipants
+	}
+
+	participants := append([]int64{user}, others...)
+
+	if blocked, err := member.Blocked(participants...); err != nil {
+		return 0, err
+	} else if blocked {
+		return 0, member.ErrBlocked
+	}
+
+	k := key(participants)
+
+	var dest model.Room
+	stmt := SELECT(Room.ID).FROM(Room).WHERE(Room.DirectKey.EQ(String(k)))
+
+	switch err := stmt.Query(globals.Database, &dest); err {
+	case nil:
+		return dest.ID, nil
+	case qrm.ErrNoRows:
+	default:
+		return 0, err
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	ins := Room.INSERT(Room.Name, Room.Description, Room.Kind, Room.DirectKey, Room.Visibility).MODEL(model.Room{
+		Kind:       member.KindDirect,
+		DirectKey:  &k,
+		Visibility: member.VisibilityPrivate,
+	}).ON_CONFLICT(Room.DirectKey).DO_NOTHING().RETURNING(Room.ID)
+
+	if err := ins.Query(tx, &dest); err == qrm.ErrNoRows {
+		// Opened concurrently by another participant
+		tx.Rollback()
+		err = stmt.Query(globals.Database, &dest)
+		return dest.ID, err
+	} else if err != nil {
+		return 0, err
+	}
+
+	members := UserRoom.INSERT(UserRoom.UserID, UserRoom.RoomID).MODELS(lo.Map(participants, func(x int64, _ int) model.UserRoom {
+		return model.UserRoom{UserID: x, RoomID: dest.ID}
+	}))
+
+	if _, err := members.Exec(tx); err != nil {
+		return 0, err
+	}
+
+	return dest.ID, tx.Commit()
+}
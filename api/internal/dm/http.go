@@ -0,0 +1,88 @@
+package dm
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// Open godoc
+// @Summary Open direct conversation
+// @Description Gets (or creates) the group of a direct conversation between the user and others, which is refused if any participant has blocked another. The group is used like any other, for history and over a WebSocket.
+// @Tags dm
+// @Produce json
+// @Success 200 {integer} int64
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param ident path string true "Comma-separated identifiers of the other participants"
+// @Router /dm/open/{ident} [post]
+func Open(c *gin.Context) {
+	var uri struct {
+		Identifier string `uri:"ident" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/dm/open] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	idents := lo.Uniq(strings.Split(uri.Identifier, ","))
+	if len(idents) >= MaxParticipants {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var others []model.UserAccount
+	stmt := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(
+		UserAccount.Identifier.IN(lo.Map(idents, func(x string, _ int) Expression { return String(x) })...),
+	)
+
+	if err := stmt.Query(globals.Database, &others); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/dm/open] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	} else if len(others) != len(idents) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	switch id, err := Conversation(user.ID, lo.Map(others, func(x model.UserAccount, _ int) int64 { return x.ID })); err {
+	default:
+		fmt.Printf("[/dm/open] Failed to open conversation: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case ErrParticipants:
+		c.Status(http.StatusBadRequest)
+	case member.ErrBlocked:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.JSON(http.StatusOK, id)
+	}
+}
+
+func HttpHandler(r *gin.RouterGroup) {
+	g := r.Group("/dm")
+	g.Use(auth.Middleware())
+	g.POST("/open/:ident", Open)
+}
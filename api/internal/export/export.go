@@ -0,0 +1,289 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatText = "txt"
+	FormatHTML = "html"
+)
+
+var Formats = map[string]string{
+	FormatJSON: "application/json",
+	FormatCSV:  "text/csv",
+	FormatText: "text/plain; charset=utf-8",
+	FormatHTML: "text/html; charset=utf-8",
+}
+
+var (
+	ErrFormat    = errors.New("unknown export format")
+	ErrNotMember = errors.New("not a member of group")
+)
+
+// batchSize is the number of messages read from the database at a time.
+const batchSize = 500
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// Query selects the messages of a group to export on behalf of a user.
+type Query struct {
+	UserID  int64
+	GroupID int64
+
+	// From and To bound the time messages were sent, if given
+	From *int64
+	To   *int64
+}
+
+// Entry is a single exported message.
+type Entry struct {
+	ID          int64  `json:"message_id"`
+	Identifier  string `json:"user_ident"`
+	DisplayName string `json:"display_name"`
+	Contents    string `json:"contents"`
+	IssuedAt    int64  `json:"iat"`
+	EditedAt    *int64 `json:"edited_at,omitempty"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
+}
+
+// encoder writes entries in one of the export formats.
+type encoder interface {
+	begin(group model.Room) error
+	encode(entry Entry) error
+	end() error
+}
+
+func (q Query) condition() BoolExpression {
+	cond := RoomMessage.RoomID.EQ(Int64(q.GroupID)).
+		AND(RoomMessage.DeletedAt.IS_NULL()).
+		AND(member.Unblocked(q.UserID, RoomMessage.UserID))
+
+	if q.From != nil {
+		cond = cond.AND(RoomMessage.Iat.GT_EQ(Int64(*q.From)))
+	}
+
+	if q.To != nil {
+		cond = cond.AND(RoomMessage.Iat.LT_EQ(Int64(*q.To)))
+	}
+
+	return cond
+}
+
+// Count gets the number of messages a query exports.
+func Count(q Query) (int64, error) {
+	var dest struct {
+		Count int64 `alias:"count"`
+	}
+
+	err := SELECT(COUNT(RoomMessage.ID).AS("count")).FROM(RoomMessage).WHERE(q.condition()).Query(globals.Database, &dest)
+	return dest.Count, err
+}
+
+// Write exports the messages selected by a query in a format, oldest first.
+// Messages are read in batches so that large groups are never held in memory.
+// Deleted messages and those from users blocked by the exporting user are left
+// out.
+func Write(w io.Writer, format string, q Query) error {
+	var enc encoder
+	switch format {
+	case FormatJSON:
+		enc = &jsonEncoder{w: w}
+	case FormatCSV:
+		enc = &csvEncoder{w: csv.NewWriter(w)}
+	case FormatText:
+		enc = &textEncoder{w: w}
+	case FormatHTML:
+		enc = &htmlEncoder{w: w}
+	default:
+		return ErrFormat
+	}
+
+	var group model.Room
+	if err := SELECT(Room.ID, Room.Name, Room.Description).FROM(Room).WHERE(Room.ID.EQ(Int64(q.GroupID))).Query(globals.Database, &group); err != nil {
+		return err
+	}
+
+	if err := enc.begin(group); err != nil {
+		return err
+	}
+
+	var last *model.RoomMessage
+	for {
+		cond := q.condition()
+		if last != nil {
+			cond = cond.AND(RoomMessage.Iat.GT(Int64(last.Iat)).OR(RoomMessage.Iat.EQ(Int64(last.Iat)).AND(RoomMessage.ID.GT(Int64(last.ID)))))
+		}
+
+		stmt := SELECT(
+			RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.EditedAt, RoomMessage.ParentID,
+			UserAccount.ID, UserAccount.Identifier, UserAccount.DisplayName,
+		).FROM(
+			RoomMessage.INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+		).WHERE(cond).ORDER_BY(RoomMessage.Iat.ASC(), RoomMessage.ID.ASC()).LIMIT(batchSize)
+
+		var dest []struct {
+			model.RoomMessage
+
+			User model.UserAccount
+		}
+
+		if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+			return err
+		}
+
+		for _, x := range dest {
+			if err := enc.encode(Entry{x.ID, x.User.Identifier, x.User.DisplayName, x.Contents, x.Iat, x.EditedAt, x.ParentID}); err != nil {
+				return err
+			}
+		}
+
+		if len(dest) < batchSize {
+			break
+		}
+
+		last = &dest[len(dest)-1].RoomMessage
+	}
+
+	return enc.end()
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) begin(group model.Room) error {
+	name, _ := json.Marshal(group.Name)
+	_, err := fmt.Fprintf(e.w, "{\"group_id\":%d,\"name\":%s,\"messages\":[", group.ID, name)
+	return err
+}
+
+func (e *jsonEncoder) encode(entry Entry) error {
+	p, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+
+	e.count++
+	_, err = e.w.Write(p)
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin(group model.Room) error {
+	return e.w.Write([]string{"message_id", "iat", "time", "user_ident", "display_name", "parent_id", "contents"})
+}
+
+func (e *csvEncoder) encode(entry Entry) error {
+	var parent string
+	if entry.ParentID != nil {
+		parent = strconv.FormatInt(*entry.ParentID, 10)
+	}
+
+	return e.w.Write([]string{
+		strconv.FormatInt(entry.ID, 10),
+		strconv.FormatInt(entry.IssuedAt, 10),
+		time.Unix(entry.IssuedAt, 0).UTC().Format(timeLayout),
+		entry.Identifier,
+		entry.DisplayName,
+		parent,
+		entry.Contents,
+	})
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type textEncoder struct {
+	w io.Writer
+}
+
+func (e *textEncoder) begin(group model.Room) error {
+	_, err := fmt.Fprintf(e.w, "%s\n%s\n\n", group.Name, group.Description)
+	return err
+}
+
+func (e *textEncoder) encode(entry Entry) error {
+	var reply string
+	if entry.ParentID != nil {
+		reply = fmt.Sprintf(" (reply to #%d)", *entry.ParentID)
+	}
+
+	_, err := fmt.Fprintf(e.w, "[%s] #%d %s (%s)%s: %s\n",
+		time.Unix(entry.IssuedAt, 0).UTC().Format(timeLayout), entry.ID, entry.DisplayName, entry.Identifier, reply, entry.Contents)
+	return err
+}
+
+func (e *textEncoder) end() error {
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"time": func(iat int64) string { return time.Unix(iat, 0).UTC().Format(timeLayout) },
+}).Parse(`{{define "begin"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>body{font-family:sans-serif}.message{margin:0.5em 0}.meta{color:#666;font-size:0.85em}.reply{margin-left:2em}</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p>{{.Description}}</p>
+{{end}}{{define "message"}}<div class="message{{if .ParentID}} reply{{end}}" id="m{{.ID}}">
+<div class="meta"><b>{{.DisplayName}}</b> ({{.Identifier}}) {{time .IssuedAt}}{{if .ParentID}} &middot; <a href="#m{{.ParentID}}">in reply</a>{{end}}</div>
+<div>{{.Contents}}</div>
+</div>
+{{end}}{{define "end"}}</body>
+</html>
+{{end}}`))
+
+type htmlEncoder struct {
+	w io.Writer
+}
+
+func (e *htmlEncoder) begin(group model.Room) error {
+	return htmlTemplate.ExecuteTemplate(e.w, "begin", group)
+}
+
+func (e *htmlEncoder) encode(entry Entry) error {
+	return htmlTemplate.ExecuteTemplate(e.w, "message", entry)
+}
+
+func (e *htmlEncoder) end() error {
+	return htmlTemplate.ExecuteTemplate(e.w, "end", nil)
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// StreamLimit is the most messages exported directly in response to a request.
+// Larger exports are run as a job.
+const StreamLimit = 5000
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+var queue = make(chan model.ExportJob, 64)
+
+// backlog is set when a job could not be queued, leaving it pending in the
+// database for the worker to pick up once the queue drains.
+var backlog atomic.Bool
+
+// Path gets the location on disk of the file produced by a job.
+func Path(job model.ExportJob) string {
+	return filepath.Join(globals.Opts.ExportFolderPath, fmt.Sprintf("%d.%s", job.ID, job.Format))
+}
+
+func (q Query) job(format string) model.ExportJob {
+	return model.ExportJob{
+		UserID:  q.UserID,
+		RoomID:  q.GroupID,
+		Format:  format,
+		FromIat: q.From,
+		ToIat:   q.To,
+	}
+}
+
+func queryOf(job model.ExportJob) Query {
+	return Query{job.UserID, job.RoomID, job.FromIat, job.ToIat}
+}
+
+// Enqueue creates a job to export the messages selected by a query in the
+// background.
+func Enqueue(format string, q Query) (model.ExportJob, error) {
+	if _, ok := Formats[format]; !ok {
+		return model.ExportJob{}, ErrFormat
+	}
+
+	job := q.job(format)
+	job.Status = StatusPending
+	job.Iat = time.Now().Unix()
+
+	var dest model.ExportJob
+	stmt := ExportJob.INSERT(
+		ExportJob.UserID,
+		ExportJob.RoomID,
+		ExportJob.Format,
+		ExportJob.FromIat,
+		ExportJob.ToIat,
+		ExportJob.Status,
+		ExportJob.Iat,
+	).MODEL(job).RETURNING(ExportJob.AllColumns)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil {
+		return model.ExportJob{}, err
+	}
+
+	// Requests are never held up by a full queue
+	select {
+	case queue <- dest:
+	default:
+		backlog.Store(true)
+	}
+
+	return dest, nil
+}
+
+// Get gets a job created by a user.
+func Get(user int64, id int64) (model.ExportJob, error) {
+	var dest model.ExportJob
+	stmt := SELECT(ExportJob.AllColumns).FROM(ExportJob).WHERE(
+		ExportJob.ID.EQ(Int64(id)).AND(ExportJob.UserID.EQ(Int64(user))),
+	)
+
+	err := stmt.Query(globals.Database, &dest)
+	return dest, err
+}
+
+func setStatus(job *model.ExportJob, status string) error {
+	job.Status = status
+	if status == StatusDone || status == StatusFailed {
+		job.CompletedAt = lo.ToPtr(time.Now().Unix())
+	}
+
+	stmt := ExportJob.UPDATE(ExportJob.Status, ExportJob.CompletedAt).MODEL(job).WHERE(ExportJob.ID.EQ(Int64(job.ID)))
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// claim marks a pending job as running, reporting false if it has already been
+// taken, such as when it was both queued and picked up from the backlog.
+func claim(job model.ExportJob) (bool, error) {
+	stmt := ExportJob.UPDATE(ExportJob.Status).SET(String(StatusRunning)).WHERE(
+		ExportJob.ID.EQ(Int64(job.ID)).AND(ExportJob.Status.EQ(String(StatusPending))),
+	)
+
+	res, err := stmt.Exec(globals.Database)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// run writes the file of a job. Jobs of users who have since left or been
+// removed from the group fail rather than export messages they can no longer
+// read.
+func run(job model.ExportJob) error {
+	if ok, err := claim(job); err != nil || !ok {
+		return err
+	}
+
+	job.Status = StatusRunning
+
+	if role, err := member.Role(job.UserID, job.RoomID); err != nil {
+		return err
+	} else if role == "" {
+		return ErrNotMember
+	}
+
+	if err := os.MkdirAll(globals.Opts.ExportFolderPath, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(globals.Opts.ExportFolderPath, "export-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	if err := Write(w, job.Format, queryOf(job)); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), Path(job)); err != nil {
+		return err
+	}
+
+	return setStatus(&job, StatusDone)
+}
+
+// requeue queues every pending job from the database.
+func requeue() {
+	var pending []model.ExportJob
+	stmt := SELECT(ExportJob.AllColumns).FROM(ExportJob).WHERE(
+		ExportJob.Status.EQ(String(StatusPending)),
+	).ORDER_BY(ExportJob.ID.ASC())
+
+	if err := stmt.Query(globals.Database, &pending); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[export] Failed to query database: %s\n", err.Error())
+	}
+
+	go func() {
+		for _, job := range pending {
+			queue <- job
+		}
+	}()
+}
+
+// Worker runs export jobs as they are enqueued. Jobs left unfinished by a
+// previous run of the server are picked up first, and jobs that did not fit in
+// the queue once it drains.
+func Worker() {
+	// Jobs running when the server stopped are started over
+	stmt := ExportJob.UPDATE(ExportJob.Status).SET(String(StatusPending)).WHERE(ExportJob.Status.EQ(String(StatusRunning)))
+	if _, err := stmt.Exec(globals.Database); err != nil {
+		fmt.Printf("[export] Failed to update database: %s\n", err.Error())
+	}
+
+	requeue()
+
+	for job := range queue {
+		if err := run(job); err != nil {
+			fmt.Printf("[export] Failed to run job %d: %s\n", job.ID, err.Error())
+
+			if err := setStatus(&job, StatusFailed); err != nil {
+				fmt.Printf("[export] Failed to update job %d: %s\n", job.ID, err.Error())
+			}
+		}
+
+		if len(queue) == 0 && backlog.Swap(false) {
+			requeue()
+		}
+	}
+}
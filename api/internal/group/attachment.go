@@ -0,0 +1,116 @@
+package group
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// Upload godoc
+// @Summary Upload attachment
+// @Description Uploads a file to a group to be attached to a message. Uploads not attached within a day are discarded.
+// @Tags group
+// @Consume mpfd
+// @Produce json
+// @Success 200 {object} chat.Attachment
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 413
+// @Failure 415
+// @Failure 500
+// @Param group_id formData int64 true "Group ID"
+// @Param file     formData file  true "File to attach"
+// @Router /group/attachment [post]
+func Upload(c *gin.Context) {
+	group, err := strconv.ParseInt(c.PostForm("group_id"), 10, 64)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/attachment] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch attachment, err := chat.Upload(user, group, file); err {
+	default:
+		fmt.Printf("[/group/attachment] Failed to upload attachment: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotMember:
+		c.Status(http.StatusForbidden)
+	case chat.ErrAttachmentSize:
+		c.Status(http.StatusRequestEntityTooLarge)
+	case chat.ErrAttachmentType:
+		c.Status(http.StatusUnsupportedMediaType)
+	case nil:
+		c.JSON(http.StatusOK, attachment)
+	}
+}
+
+// Download godoc
+// @Summary Download attachment
+// @Description Downloads a file attached to a message in a group the user belongs to
+// @Tags group
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Attachment ID"
+// @Router /group/attachment/{id} [get]
+func Download(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/attachment] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch attachment, err := chat.Download(user, uri.ID); err {
+	default:
+		fmt.Printf("[/group/attachment] Failed to get attachment: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrNotMember:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Header("Content-Type", attachment.ContentType)
+		c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Name}))
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.File(chat.AttachmentPath(attachment.Hash))
+	}
+}
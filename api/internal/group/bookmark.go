@@ -0,0 +1,100 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+type BookmarkRequest struct {
+	MessageID int64  `json:"message_id"`
+	Note      string `json:"note"`
+}
+
+// Bookmark godoc
+// @Summary Bookmark message
+// @Description Saves a message for the user with an optional note (<= 256 characters). Bookmarking a message again replaces its note.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body BookmarkRequest true "Message to bookmark"
+// @Router /group/bookmark [post]
+func Bookmark(c *gin.Context) {
+	var request BookmarkRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/bookmark] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Bookmark(user, request.MessageID, request.Note); err {
+	default:
+		fmt.Printf("[/group/bookmark] Failed to add bookmark: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrNote:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type UnbookmarkRequest struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// Unbookmark godoc
+// @Summary Remove bookmark
+// @Description Removes the user's bookmark of a message
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body UnbookmarkRequest true "Message to remove the bookmark of"
+// @Router /group/unbookmark [post]
+func Unbookmark(c *gin.Context) {
+	var request UnbookmarkRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/unbookmark] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Unbookmark(user, request.MessageID); err {
+	default:
+		fmt.Printf("[/group/unbookmark] Failed to remove bookmark: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,264 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+const (
+	MaxNameLength        = 64
+	MaxDescriptionLength = 512
+)
+
+type CreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+	ParentID    *int64 `json:"parent_id"`
+}
+
+// Create godoc
+// @Summary Create group
+// @Description Creates a study group owned by the user, optionally under a course group. Public groups are listed to everyone, unlisted groups only to those given their ID, and private groups may only be read and joined by invite.
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {integer} int64
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body CreateRequest true "Group to create"
+// @Router /group/create [post]
+func Create(c *gin.Context) {
+	var request CreateRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	request.Description = strings.TrimSpace(request.Description)
+
+	if len(request.Name) == 0 || len(request.Name) > MaxNameLength || len(request.Description) > MaxDescriptionLength {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	switch request.Visibility {
+	case "":
+		request.Visibility = member.VisibilityPublic
+	case member.VisibilityPublic, member.VisibilityUnlisted, member.VisibilityPrivate:
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.ParentID != nil {
+		if kind, err := member.Kind(*request.ParentID); err != nil {
+			fmt.Printf("[/group/create] Error querying database: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		} else if kind != member.KindCourse {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+	}
+
+	name, err := moderation.Check(nil, request.Name)
+	if err != nil {
+		if err == moderation.ErrRejected {
+			c.Status(http.StatusBadRequest)
+		} else {
+			fmt.Printf("[/group/create] Failed to moderate name: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	description, err := moderation.Check(nil, request.Description)
+	if err != nil {
+		if err == moderation.ErrRejected {
+			c.Status(http.StatusBadRequest)
+		} else {
+			fmt.Printf("[/group/create] Failed to moderate description: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/group/create] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		fmt.Printf("[/group/create] Failed to begin transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	var dest model.Room
+	ins := Room.INSERT(Room.Name, Room.Description, Room.Kind, Room.Visibility, Room.ParentID).MODEL(model.Room{
+		Name:        name.Contents,
+		Description: description.Contents,
+		Kind:        member.KindGroup,
+		Visibility:  request.Visibility,
+		ParentID:    request.ParentID,
+	}).RETURNING(Room.ID)
+
+	if err := ins.Query(tx, &dest); err != nil {
+		fmt.Printf("[/group/create] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	owner := UserRoom.INSERT(UserRoom.UserID, UserRoom.RoomID, UserRoom.Role).MODEL(model.UserRoom{
+		UserID: user.ID,
+		RoomID: dest.ID,
+		Role:   member.RoleOwner,
+	})
+
+	if _, err := owner.Exec(tx); err != nil {
+		fmt.Printf("[/group/create] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("[/group/create] Failed to commit transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(name.Flagged) > 0 {
+		if err := moderation.Flag(user.ID, &dest.ID, nil, moderation.FieldGroupName, request.Name, name.Flagged); err != nil {
+			fmt.Printf("[/group/create] Failed to flag name: %s\n", err.Error())
+		}
+	}
+
+	if len(description.Flagged) > 0 {
+		if err := moderation.Flag(user.ID, &dest.ID, nil, moderation.FieldGroupDesc, request.Description, description.Flagged); err != nil {
+			fmt.Printf("[/group/create] Failed to flag description: %s\n", err.Error())
+		}
+	}
+
+	c.JSON(http.StatusOK, dest.ID)
+}
+
+type DescribeRequest struct {
+	GroupID     int64  `json:"group_id"`
+	Description string `json:"description"`
+}
+
+// Describe godoc
+// @Summary Change group description
+// @Description Replaces the description of a group and announces the change to it
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body DescribeRequest true "New description"
+// @Router /group/describe [post]
+func Describe(c *gin.Context) {
+	var request DescribeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	request.Description = strings.TrimSpace(request.Description)
+	if len(request.Description) > MaxDescriptionLength {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	user, ok := expectModerator(c, "/group/describe", request.GroupID)
+	if !ok {
+		return
+	}
+
+	description, err := moderation.Check(&request.GroupID, request.Description)
+	if err != nil {
+		if err == moderation.ErrRejected {
+			c.Status(http.StatusBadRequest)
+		} else {
+			fmt.Printf("[/group/describe] Failed to moderate description: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		fmt.Printf("[/group/describe] Failed to begin transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	upd := Room.UPDATE(Room.Description).SET(String(description.Contents)).WHERE(Room.ID.EQ(Int64(request.GroupID)))
+	if _, err := upd.Exec(tx); err != nil {
+		fmt.Printf("[/group/describe] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	msg, err := chat.Announce(tx, request.GroupID, chat.SystemEvent{
+		Event:       chat.SystemDescription,
+		Moderator:   user.Identifier,
+		Description: &description.Contents,
+	})
+
+	if err != nil {
+		fmt.Printf("[/group/describe] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("[/group/describe] Failed to commit transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	hub.Publish(request.GroupID, msg, nil)
+
+	if len(description.Flagged) > 0 {
+		if err := moderation.Flag(user.ID, &request.GroupID, nil, moderation.FieldGroupDesc, request.Description, description.Flagged); err != nil {
+			fmt.Printf("[/group/describe] Failed to flag description: %s\n", err.Error())
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
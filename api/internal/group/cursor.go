@@ -0,0 +1,49 @@
+package group
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// cursor marks a position in a list of messages for keyset pagination. It is
+// handed to clients as an opaque string.
+type cursor struct {
+	Rank *float64 `json:"r,omitempty"`
+	Iat  int64    `json:"t"`
+	ID   int64    `json:"i"`
+}
+
+func (c cursor) String() string {
+	return encodeCursor(c)
+}
+
+func parseCursor(s string) (cursor, error) {
+	var c cursor
+	err := decodeCursor(s, &c)
+	return c, err
+}
+
+// memberCursor marks a position in a list of members.
+type memberCursor struct {
+	Rank int64  `json:"r"`
+	Name string `json:"n"`
+	ID   int64  `json:"i"`
+}
+
+func (c memberCursor) String() string {
+	return encodeCursor(c)
+}
+
+func encodeCursor(v any) string {
+	p, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(p)
+}
+
+func decodeCursor(s string, v any) error {
+	p, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(p, v)
+}
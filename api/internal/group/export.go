@@ -0,0 +1,219 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/export"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+type ExportJobResponse struct {
+	ID          int64  `json:"job_id"`
+	GroupID     int64  `json:"group_id"`
+	Format      string `json:"format"`
+	Status      string `json:"status"`
+	IssuedAt    int64  `json:"iat"`
+	CompletedAt *int64 `json:"completed_at,omitempty"`
+}
+
+func newExportJobResponse(job model.ExportJob) ExportJobResponse {
+	return ExportJobResponse{job.ID, job.RoomID, job.Format, job.Status, job.Iat, job.CompletedAt}
+}
+
+func exportFilename(group int64, format string) string {
+	return fmt.Sprintf("group-%d-%s.%s", group, time.Now().UTC().Format("20060102"), format)
+}
+
+// Export godoc
+// @Summary Export group messages
+// @Description Exports the messages of a group, oldest first, leaving out deleted messages and those from blocked users. Small exports are downloaded directly; larger ones respond with a job to poll until its file is ready.
+// @Tags group
+// @Produce json
+// @Success 200
+// @Success 202 {object} ExportJobResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id     path  int64  true  "Group ID"
+// @Param format query string false "One of json (default), csv, txt, or html"
+// @Param from   query int64  false "Only include messages sent at or after this UTC time"
+// @Param to     query int64  false "Only include messages sent at or before this UTC time"
+// @Router /group/export/{id} [get]
+func Export(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Format string `form:"format"`
+		From   *int64 `form:"from"`
+		To     *int64 `form:"to"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = export.FormatJSON
+	}
+
+	contentType, ok := export.Formats[request.Format]
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/export] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if role, err := member.Role(user.ID, uri.ID); err != nil {
+		fmt.Printf("[/group/export] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	} else if role == "" {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	query := export.Query{UserID: user.ID, GroupID: uri.ID, From: request.From, To: request.To}
+
+	count, err := export.Count(query)
+	if err != nil {
+		fmt.Printf("[/group/export] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if count > export.StreamLimit {
+		if job, err := export.Enqueue(request.Format, query); err != nil {
+			fmt.Printf("[/group/export] Failed to enqueue export: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+		} else {
+			c.JSON(http.StatusAccepted, newExportJobResponse(job))
+		}
+
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename(uri.ID, request.Format)))
+	c.Status(http.StatusOK)
+
+	if err := export.Write(c.Writer, request.Format, query); err != nil {
+		// The response has already begun, so the download is left truncated
+		fmt.Printf("[/group/export] Failed to write export: %s\n", err.Error())
+	}
+}
+
+// expectExportJob gets an export job created by the user making a request, as
+// long as they are still a member of its group. The second return value is
+// false if a response has already been written.
+func expectExportJob(c *gin.Context, route string) (model.ExportJob, bool) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return model.ExportJob{}, false
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return model.ExportJob{}, false
+	}
+
+	job, err := export.Get(user.ID, uri.ID)
+	if err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+		return model.ExportJob{}, false
+	} else if err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return model.ExportJob{}, false
+	}
+
+	if role, err := member.Role(user.ID, job.RoomID); err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return model.ExportJob{}, false
+	} else if role == "" {
+		c.Status(http.StatusForbidden)
+		return model.ExportJob{}, false
+	}
+
+	return job, true
+}
+
+// ExportStatus godoc
+// @Summary Get export job
+// @Description Gets the status of an export job created by the user, who must still be a member of its group
+// @Tags group
+// @Produce json
+// @Success 200 {object} ExportJobResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Job ID"
+// @Router /group/exports/{id} [get]
+func ExportStatus(c *gin.Context) {
+	if job, ok := expectExportJob(c, "/group/exports"); ok {
+		c.JSON(http.StatusOK, newExportJobResponse(job))
+	}
+}
+
+// ExportFile godoc
+// @Summary Download export
+// @Description Downloads the file produced by a finished export job. The user must still be a member of its group.
+// @Tags group
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Param id path int64 true "Job ID"
+// @Router /group/exports/{id}/file [get]
+func ExportFile(c *gin.Context) {
+	job, ok := expectExportJob(c, "/group/exports")
+	if !ok {
+		return
+	}
+
+	if job.Status != export.StatusDone {
+		c.Status(http.StatusConflict)
+		return
+	}
+
+	c.Header("Content-Type", export.Formats[job.Format])
+	c.FileAttachment(export.Path(job), exportFilename(job.RoomID, job.Format))
+}
@@ -0,0 +1,294 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 100
+)
+
+type HistoryResponseItem struct {
+	ID         int64           `json:"message_id"`
+	Identifier string          `json:"user_ident,omitempty"`
+	Kind       string          `json:"kind"`
+	Contents   string          `json:"contents"`
+	IssuedAt   int64           `json:"iat"`
+	EditedAt   *int64          `json:"edited_at,omitempty"`
+	DeletedAt  *int64          `json:"deleted_at,omitempty"`
+	ParentID   *int64          `json:"parent_id,omitempty"`
+	Replies    int64           `json:"reply_count"`
+	LastReply  *int64          `json:"last_reply_iat,omitempty"`
+	Reactions  []chat.Reaction `json:"reactions"`
+
+	Attachments []chat.Attachment `json:"attachments,omitempty"`
+	Poll        *chat.PollState   `json:"poll,omitempty"`
+	System      *chat.SystemEvent `json:"system,omitempty"`
+}
+
+type HistoryResponse struct {
+	Messages []HistoryResponseItem `json:"messages"`
+
+	// Next continues towards older messages, Prev towards newer ones
+	Next *string `json:"next_cursor"`
+	Prev *string `json:"prev_cursor"`
+}
+
+func cursorOf(msg model.RoomMessage) *string {
+	return lo.ToPtr(cursor{Iat: msg.Iat, ID: msg.ID}.String())
+}
+
+// historyPage gets up to limit top-level messages of a group, newest first, on
+// one side of a position in its history, leaving out those by users the viewer
+// has blocked. A nil position starts from the newest
+// message. The second return value is true if there are further messages on
+// the same side beyond the page.
+func historyPage(group int64, viewer int64, pos *cursor, older bool, inclusive bool, limit int64) ([]messageRow, bool, error) {
+	cond := RoomMessage.RoomID.EQ(Int64(group)).
+		AND(RoomMessage.ParentID.IS_NULL()).
+		AND(member.Unblocked(viewer, RoomMessage.UserID))
+	order := []OrderByClause{RoomMessage.Iat.DESC(), RoomMessage.ID.DESC()}
+
+	if pos != nil {
+		iat, id := Int64(pos.Iat), Int64(pos.ID)
+
+		if older {
+			tie := RoomMessage.ID.LT(id)
+			if inclusive {
+				tie = RoomMessage.ID.LT_EQ(id)
+			}
+
+			cond = cond.AND(RoomMessage.Iat.LT(iat).OR(RoomMessage.Iat.EQ(iat).AND(tie)))
+		} else {
+			cond = cond.AND(RoomMessage.Iat.GT(iat).OR(RoomMessage.Iat.EQ(iat).AND(RoomMessage.ID.GT(id))))
+			order = []OrderByClause{RoomMessage.Iat.ASC(), RoomMessage.ID.ASC()}
+		}
+	}
+
+	stmt := SELECT(
+		RoomMessage.ID, RoomMessage.Kind, RoomMessage.Payload, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.EditedAt, RoomMessage.DeletedAt,
+		UserAccount.Identifier,
+	).FROM(
+		RoomMessage.LEFT_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(order...).LIMIT(limit + 1)
+
+	var dest []messageRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, false, err
+	}
+
+	more := int64(len(dest)) > limit
+	if more {
+		dest = dest[:limit]
+	}
+
+	if pos != nil && !older {
+		dest = lo.Reverse(dest)
+	}
+
+	return dest, more, nil
+}
+
+// History godoc
+// @Summary Gets group messages
+// @Description Gets top-level message history from a group in descending order, with thread reply counts. Messages by blocked users are left out. Without an anchor the newest messages are returned. At most one of before, after, or around may be given.
+// @Tags group
+// @Produce json
+// @Success 200 {object} HistoryResponse
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Param id     path  int64  true  "Group ID"
+// @Param limit  query int64  false "Max number of messages to retreive (<= 100, default 50)"
+// @Param before query string false "Cursor; gets messages older than it"
+// @Param after  query string false "Cursor; gets messages newer than it"
+// @Param around query int64  false "Message ID; gets messages surrounding and including it"
+// @Router /group/history/{id} [get]
+func History(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	caller, ok := expectVisible(c, "/group/history", uri.ID)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Limit  int64  `form:"limit"`
+		Before string `form:"before"`
+		After  string `form:"after"`
+		Around int64  `form:"around"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = DefaultHistoryLimit
+	} else if request.Limit > MaxHistoryLimit {
+		request.Limit = MaxHistoryLimit
+	}
+
+	anchors := lo.Count([]bool{request.Before != "", request.After != "", request.Around != 0}, true)
+	if anchors > 1 {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var pos cursor
+	for _, s := range []string{request.Before, request.After} {
+		if s == "" {
+			continue
+		}
+
+		var err error
+		if pos, err = parseCursor(s); err != nil || pos.Rank != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var response HistoryResponse
+	var dest []messageRow
+	var err error
+
+	switch {
+	case request.Before != "":
+		var more bool
+		if dest, more, err = historyPage(uri.ID, caller, &pos, true, false, request.Limit); err == nil {
+			if len(dest) > 0 {
+				response.Prev = cursorOf(dest[0].RoomMessage)
+			}
+
+			if more {
+				response.Next = cursorOf(dest[len(dest)-1].RoomMessage)
+			}
+		}
+	case request.After != "":
+		var more bool
+		if dest, more, err = historyPage(uri.ID, caller, &pos, false, false, request.Limit); err == nil {
+			if len(dest) > 0 {
+				response.Next = cursorOf(dest[len(dest)-1].RoomMessage)
+			}
+
+			if more {
+				response.Prev = cursorOf(dest[0].RoomMessage)
+			}
+		}
+	case request.Around != 0:
+		var target model.RoomMessage
+		stmt := SELECT(RoomMessage.ID, RoomMessage.Iat, RoomMessage.ParentID).FROM(RoomMessage).WHERE(
+			RoomMessage.ID.EQ(Int64(request.Around)).AND(RoomMessage.RoomID.EQ(Int64(uri.ID))),
+		)
+
+		if err = stmt.Query(globals.Database, &target); err == qrm.ErrNoRows {
+			c.Status(http.StatusBadRequest)
+			return
+		} else if err != nil {
+			break
+		}
+
+		// Replies are found in history by their thread's parent
+		if target.ParentID != nil {
+			if err = SELECT(RoomMessage.ID, RoomMessage.Iat).FROM(RoomMessage).WHERE(RoomMessage.ID.EQ(Int64(*target.ParentID))).Query(globals.Database, &target); err != nil {
+				break
+			}
+		}
+
+		pos = cursor{Iat: target.Iat, ID: target.ID}
+
+		var older, newer []messageRow
+		var moreOlder, moreNewer bool
+
+		// The target falls on the older side, so a single message is the target
+		// alone. An empty side still reports whether it has further messages.
+		if older, moreOlder, err = historyPage(uri.ID, caller, &pos, true, true, request.Limit-request.Limit/2); err != nil {
+			break
+		}
+
+		if newer, moreNewer, err = historyPage(uri.ID, caller, &pos, false, false, request.Limit/2); err != nil {
+			break
+		}
+
+		// The target itself may be hidden from the viewer, leaving either side empty
+		dest = append(newer, older...)
+		if moreOlder && len(dest) > 0 {
+			response.Next = cursorOf(dest[len(dest)-1].RoomMessage)
+		}
+
+		if moreNewer && len(dest) > 0 {
+			response.Prev = cursorOf(dest[0].RoomMessage)
+		}
+	default:
+		var more bool
+		if dest, more, err = historyPage(uri.ID, caller, nil, true, false, request.Limit); err == nil && more {
+			response.Next = cursorOf(dest[len(dest)-1].RoomMessage)
+		}
+	}
+
+	if err != nil {
+		fmt.Printf("[/group/history] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	replies, err := replySummaries(messageIDs(dest), caller)
+	if err != nil {
+		fmt.Printf("[/group/history] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	extras, err := loadExtras(c, dest)
+	if err != nil {
+		fmt.Printf("[/group/history] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response.Messages = lo.Map(dest, func(x messageRow, _ int) HistoryResponseItem {
+		item := HistoryResponseItem{
+			ID:          x.ID,
+			Identifier:  x.User.Identifier,
+			Kind:        x.Kind,
+			Contents:    x.Contents,
+			IssuedAt:    x.Iat,
+			EditedAt:    x.EditedAt,
+			DeletedAt:   x.DeletedAt,
+			Reactions:   extras.reactionsOf(x.ID),
+			Attachments: extras.attachments[x.ID],
+			Poll:        extras.pollOf(x.ID),
+			System:      chat.SystemOf(x.RoomMessage),
+		}
+
+		if summary, ok := replies[x.ID]; ok {
+			item.Replies = summary.Count
+			item.LastReply = &summary.Last
+		}
+
+		return item
+	})
+
+	c.JSON(http.StatusOK, response)
+}
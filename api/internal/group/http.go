@@ -11,13 +11,19 @@ import (
 
 	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
 	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
 	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/retention"
 )
 
 type AllResponseItem struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
 }
 
 // All godoc
@@ -30,7 +36,7 @@ type AllResponseItem struct {
 // @Router /group/all [get]
 func All(c *gin.Context) {
 	var dest []model.Room
-	stmt := SELECT(Room.ID, Room.Name, Room.Description).FROM(Room)
+	stmt := SELECT(Room.ID, Room.Name, Room.Description, Room.Kind, Room.ParentID).FROM(Room).WHERE(Room.Visibility.EQ(String(member.VisibilityPublic)))
 
 	if err := stmt.Query(globals.Database, &dest); err != nil {
 		fmt.Printf("[/group/all] Error querying database: %s\n", err.Error())
@@ -39,14 +45,20 @@ func All(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, lo.Map(dest, func(x model.Room, _ int) AllResponseItem {
-		return AllResponseItem{x.ID, x.Name, x.Description}
+		return AllResponseItem{x.ID, x.Name, x.Description, x.Kind, x.ParentID}
 	}))
 }
 
 type GetResponse struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Kind        string           `json:"kind"`
+	Visibility  string           `json:"visibility"`
+	ParentID    *int64           `json:"parent_id,omitempty"`
+	Members     int64            `json:"member_count"`
+	Retention   retention.Policy `json:"retention"`
+	Posting     chat.Policy      `json:"posting"`
 }
 
 // Get godoc
@@ -56,6 +68,7 @@ type GetResponse struct {
 // @Produce json
 // @Success 200 {object} GetResponse
 // @Failure 400
+// @Failure 403
 // @Failure 500
 // @Param id path int64 true "Group ID"
 // @Router /group/get/{id} [get]
@@ -69,173 +82,98 @@ func Get(c *gin.Context) {
 		return
 	}
 
+	if _, ok := expectVisible(c, "/group/get", uri.ID); !ok {
+		return
+	}
+
 	var dest model.Room
-	stmt := SELECT(Room.ID, Room.Name, Room.Description).FROM(Room).WHERE(Room.ID.EQ(Int64(uri.ID)))
+	stmt := SELECT(Room.ID, Room.Name, Room.Description, Room.Kind, Room.Visibility, Room.ParentID, Room.Retention, Room.RetentionDays, Room.SlowMode, Room.Announcement, Room.MemberDays).FROM(Room).WHERE(Room.ID.EQ(Int64(uri.ID)))
 
 	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
 		c.Status(http.StatusBadRequest)
+		return
 	} else if err != nil {
 		fmt.Printf("[/group/get] Error querying database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
-	} else {
-		c.JSON(http.StatusOK, GetResponse{
-			dest.ID,
-			dest.Name,
-			dest.Description,
-		})
-	}
-}
-
-type SearchResponseItem struct {
-	ID         int64  `json:"message_id"`
-	Identifier string `json:"user_ident"`
-	Contents   string `json:"contents"`
-	IssuedAt   int64  `json:"iat"`
-}
-
-// Search godoc
-// @Summary Searchs messages
-// @Description Searches for a message in a group
-// @Tags group
-// @Produce json
-// @Success 200 {array} SearchResponseItem
-// @Failure 500
-// @Param id      path  int64 true  "Group ID"
-// @Param content query string true "Content to search messages for"
-// @Param limit   query int64 true  "Max number of messages to retreive (<= 20)"
-// @Router /group/search/{id} [get]
-func Search(c *gin.Context) {
-	var uri struct {
-		ID int64 `uri:"id" binding:"required"`
-	}
-
-	if err := c.ShouldBindUri(&uri); err != nil {
-		c.Status(http.StatusBadRequest)
 		return
 	}
 
-	var request struct {
-		Limit   int64  `form:"limit" binding:"required"`
-		Content string `form:"content" binding:"required"`
-	}
-
-	if err := c.BindQuery(&request); err != nil {
-		c.Status(http.StatusBadRequest)
+	count, err := countMembers(dest.ID)
+	if err != nil {
+		fmt.Printf("[/group/get] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
 		return
 	}
 
-	if request.Limit > 20 {
-		request.Limit = 20
-	}
-
-	stmt := SELECT(
-		RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat,
-		UserAccount.Identifier,
-	).FROM(
-		RoomMessage.INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
-	).WHERE(
-		RoomMessage.Contents.LIKE(String(request.Content)),
-	).ORDER_BY(RoomMessage.Iat.DESC()).LIMIT(request.Limit)
-
-	var dest []struct {
-		model.RoomMessage
-
-		User model.UserAccount
-	}
+	c.JSON(http.StatusOK, GetResponse{
+		dest.ID,
+		dest.Name,
+		dest.Description,
+		dest.Kind,
+		dest.Visibility,
+		dest.ParentID,
+		count,
+		retention.Of(dest),
+		chat.PolicyOf(dest),
+	})
+}
 
-	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
-		fmt.Printf("[/group/search] Error querying database: %s\n", err.Error())
-		c.Status(http.StatusInternalServerError)
-		return
-	}
+// messageRow is a message joined with its author.
+type messageRow struct {
+	model.RoomMessage
 
-	c.JSON(http.StatusOK, lo.Map(dest, func(x struct {
-		model.RoomMessage
-		User model.UserAccount
-	}, _ int) SearchResponseItem {
-		return SearchResponseItem{
-			x.ID,
-			x.User.Identifier,
-			x.Contents,
-			x.Iat,
-		}
-	}))
+	User model.UserAccount
 }
 
-type HistoryResponseItem struct {
-	ID         int64  `json:"message_id"`
-	Identifier string `json:"user_ident"`
-	Contents   string `json:"contents"`
-	IssuedAt   int64  `json:"iat"`
+func messageIDs(rows []messageRow) []int64 {
+	return lo.Map(rows, func(x messageRow, _ int) int64 { return x.ID })
 }
 
-// History godoc
-// @Summary Gets group messages
-// @Description Gets message history from a group in descending order
-// @Tags group
-// @Produce json
-// @Success 200 {array} HistoryResponseItem
-// @Failure 500
-// @Param id     path  int64 true "Group ID"
-// @Param limit  query int64 true "Max number of messages to retreive (<= 20)"
-// @Param before query int64 true "UTC time cutoff; searches in reverse from this point (inclusive)"
-// @Router /group/history/{id} [get]
-func History(c *gin.Context) {
-	var uri struct {
-		ID int64 `uri:"id" binding:"required"`
-	}
+// messageExtras holds what is aggregated alongside a page of messages.
+type messageExtras struct {
+	reactions   map[int64][]chat.Reaction
+	attachments map[int64][]chat.Attachment
+	polls       map[int64]chat.PollState
+}
 
-	if err := c.ShouldBindUri(&uri); err != nil {
-		c.Status(http.StatusBadRequest)
-		return
+func loadExtras(c *gin.Context, rows []messageRow) (messageExtras, error) {
+	caller, err := callerID(c)
+	if err != nil {
+		return messageExtras{}, err
 	}
 
-	var request struct {
-		Limit  int64 `form:"limit" binding:"required"`
-		Before int64 `form:"before" binding:"required"`
+	reactions, err := chat.Reactions(messageIDs(rows), caller)
+	if err != nil {
+		return messageExtras{}, err
 	}
 
-	if err := c.BindQuery(&request); err != nil {
-		c.Status(http.StatusBadRequest)
-		return
+	attachments, err := chat.Attachments(messageIDs(rows))
+	if err != nil {
+		return messageExtras{}, err
 	}
 
-	if request.Limit > 20 {
-		request.Limit = 20
+	polls, err := chat.Polls(messageIDs(rows), caller)
+	if err != nil {
+		return messageExtras{}, err
 	}
 
-	stmt := SELECT(
-		RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat,
-		UserAccount.Identifier,
-	).FROM(
-		RoomMessage.INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
-	).WHERE(
-		RoomMessage.RoomID.EQ(Int64(uri.ID)).AND(RoomMessage.Iat.LT_EQ(Int64(request.Before))),
-	).ORDER_BY(RoomMessage.Iat.DESC()).LIMIT(request.Limit)
-
-	var dest []struct {
-		model.RoomMessage
+	return messageExtras{reactions, attachments, polls}, nil
+}
 
-		User model.UserAccount
+func (e messageExtras) pollOf(id int64) *chat.PollState {
+	if poll, ok := e.polls[id]; ok {
+		return &poll
 	}
 
-	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
-		fmt.Printf("[/group/history] Error querying database: %s\n", err.Error())
-		c.Status(http.StatusInternalServerError)
-		return
+	return nil
+}
+
+func (e messageExtras) reactionsOf(id int64) []chat.Reaction {
+	if reactions, ok := e.reactions[id]; ok {
+		return reactions
 	}
 
-	c.JSON(http.StatusOK, lo.Map(dest, func(x struct {
-		model.RoomMessage
-		User model.UserAccount
-	}, _ int) HistoryResponseItem {
-		return HistoryResponseItem{
-			x.ID,
-			x.User.Identifier,
-			x.Contents,
-			x.Iat,
-		}
-	}))
+	return []chat.Reaction{}
 }
 
 type PopularResponseItem struct {
@@ -269,6 +207,8 @@ func Popular(c *gin.Context) {
 	).FROM(
 		Room.
 			LEFT_JOIN(UserRoom, Room.ID.EQ(UserRoom.RoomID)),
+	).WHERE(
+		Room.Visibility.EQ(String(member.VisibilityPublic)),
 	).GROUP_BY(Room.ID).ORDER_BY(COUNT(Room.ID).DESC()).LIMIT(uri.Count)
 
 	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
@@ -292,4 +232,50 @@ func HttpHandler(r *gin.RouterGroup) {
 	g.GET("/search/:id", Search)
 	g.GET("/history/:id", History)
 	g.GET("/popular/:count", Popular)
+	g.GET("/revisions/:message_id", Revisions)
+	g.GET("/thread/:message_id", Thread)
+	g.GET("/pins/:id", Pins)
+	g.GET("/members/:id", Members)
+
+	g.Use(auth.Middleware())
+	g.POST("/create", Create)
+	g.POST("/describe", Describe)
+	g.POST("/edit", Edit)
+	g.POST("/delete", Delete)
+	g.POST("/react", React)
+	g.POST("/unreact", Unreact)
+	g.POST("/bookmark", Bookmark)
+	g.POST("/unbookmark", Unbookmark)
+	g.POST("/pin", Pin)
+	g.POST("/unpin", Unpin)
+	g.POST("/attachment", Upload)
+	g.GET("/attachment/:id", Download)
+	g.GET("/filters/:id", Filters)
+	g.POST("/filter", Filter)
+	g.POST("/unfilter", Unfilter)
+	g.POST("/report", Report)
+	g.GET("/reports", Reports)
+	g.POST("/resolve", Resolve)
+	g.GET("/log/:id", Log)
+	g.GET("/export/:id", Export)
+	g.GET("/exports/:id", ExportStatus)
+	g.GET("/exports/:id/file", ExportFile)
+	g.POST("/schedule", Schedule)
+	g.GET("/scheduled", Scheduled)
+	g.POST("/reschedule", Reschedule)
+	g.POST("/unschedule", Unschedule)
+	g.POST("/retention", Retention)
+	g.POST("/posting", Posting)
+	g.POST("/vote", Vote)
+	g.POST("/closepoll", ClosePoll)
+	g.POST("/invite", Invite)
+	g.GET("/invites/:id", Invites)
+	g.GET("/invite/members/:id", InviteMembers)
+	g.POST("/invite/revoke", RevokeInvite)
+	g.POST("/invite/accept/:code", AcceptInvite)
+	g.POST("/kick", Kick)
+	g.POST("/ban", Ban)
+	g.POST("/mute", Mute)
+	g.POST("/lift", Lift)
+	g.GET("/sanctions/:id", Sanctions)
 }
@@ -0,0 +1,263 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/invite"
+)
+
+type InviteResponseItem struct {
+	ID        int64  `json:"id"`
+	Code      string `json:"code"`
+	GroupID   int64  `json:"group_id"`
+	CreatedBy string `json:"created_by"`
+	MaxUses   *int64 `json:"max_uses,omitempty"`
+	Uses      int64  `json:"uses"`
+	Members   int64  `json:"members"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+	RevokedAt *int64 `json:"revoked_at,omitempty"`
+}
+
+func newInviteResponseItem(x invite.Usage) InviteResponseItem {
+	return InviteResponseItem{x.ID, x.Code, x.RoomID, x.Creator.Identifier, x.MaxUses, x.Uses, x.Members, x.Iat, x.ExpiresAt, x.RevokedAt}
+}
+
+// inviteStatus writes the response for an error from the invite package.
+func inviteStatus(c *gin.Context, route string, err error) {
+	switch err {
+	default:
+		fmt.Printf("[%s] Failed to manage invite: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+	case invite.ErrNotFound, invite.ErrInvalid, invite.ErrVisibility:
+		c.Status(http.StatusBadRequest)
+	case invite.ErrForbidden, invite.ErrBanned:
+		c.Status(http.StatusForbidden)
+	case invite.ErrExpired:
+		c.Status(http.StatusGone)
+	}
+}
+
+type InviteRequest struct {
+	GroupID   int64  `json:"group_id"`
+	ExpiresAt *int64 `json:"expires_at"`
+	MaxUses   *int64 `json:"max_uses"`
+}
+
+// Invite godoc
+// @Summary Create invite
+// @Description Creates an invite code to an unlisted or private group, optionally expiring at a UTC time or after a number of uses. Any member may invite others to an unlisted group, but only moderators to a private one.
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {object} InviteResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body InviteRequest true "Group and limits of the invite"
+// @Router /group/invite [post]
+func Invite(c *gin.Context) {
+	var request InviteRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/invite] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if dest, err := invite.Create(user.ID, request.GroupID, invite.Limits{ExpiresAt: request.ExpiresAt, MaxUses: request.MaxUses}); err != nil {
+		inviteStatus(c, "/group/invite", err)
+	} else {
+		c.JSON(http.StatusOK, newInviteResponseItem(invite.Usage{RoomInvite: dest, Creator: user}))
+	}
+}
+
+// Invites godoc
+// @Summary Gets invites
+// @Description Gets the invites to a group, newest first, with how many current members joined through each. Moderators see every invite, while other members only see their own.
+// @Tags group
+// @Produce json
+// @Success 200 {array} InviteResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param id path int64 true "Group ID"
+// @Router /group/invites/{id} [get]
+func Invites(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/invites] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	dest, err := invite.List(user.ID, uri.ID)
+	if err != nil {
+		fmt.Printf("[/group/invites] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x invite.Usage, _ int) InviteResponseItem {
+		return newInviteResponseItem(x)
+	}))
+}
+
+type InviteMembersResponseItem struct {
+	Identifier  string `json:"ident"`
+	DisplayName string `json:"display_name"`
+}
+
+// InviteMembers godoc
+// @Summary Gets members joined by invite
+// @Description Gets the current members of a group who joined through an invite, for moderators to trace a leaked link
+// @Tags group
+// @Produce json
+// @Success 200 {array} InviteMembersResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Invite ID"
+// @Router /group/invite/members/{id} [get]
+func InviteMembers(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/invite/members] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if dest, err := invite.Members(user.ID, uri.ID); err != nil {
+		inviteStatus(c, "/group/invite/members", err)
+	} else {
+		c.JSON(http.StatusOK, lo.Map(dest, func(x model.UserAccount, _ int) InviteMembersResponseItem {
+			return InviteMembersResponseItem{x.Identifier, x.DisplayName}
+		}))
+	}
+}
+
+type RevokeInviteRequest struct {
+	ID int64 `json:"id"`
+
+	// RemoveMembers also removes everyone who joined through the invite
+	RemoveMembers bool `json:"remove_members"`
+}
+
+type RevokeInviteResponse struct {
+	Removed int64 `json:"removed"`
+}
+
+// RevokeInvite godoc
+// @Summary Revoke invite
+// @Description Stops an invite from being used. Its creator or a moderator may revoke it, and moderators may also remove the members who joined through it, which is announced to the group and closes their sockets on it.
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {object} RevokeInviteResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body RevokeInviteRequest true "Invite to revoke"
+// @Router /group/invite/revoke [post]
+func RevokeInvite(c *gin.Context) {
+	var request RevokeInviteRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/invite/revoke] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if removed, err := invite.Revoke(user, request.ID, request.RemoveMembers); err != nil {
+		inviteStatus(c, "/group/invite/revoke", err)
+	} else {
+		c.JSON(http.StatusOK, RevokeInviteResponse{removed})
+	}
+}
+
+// AcceptInvite godoc
+// @Summary Accept invite
+// @Description Joins the user to the group of an invite, responding with the group ID
+// @Tags group
+// @Produce json
+// @Success 200 {integer} int64
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 410
+// @Failure 500
+// @Param code path string true "Invite code"
+// @Router /group/invite/accept/{code} [post]
+func AcceptInvite(c *gin.Context) {
+	var uri struct {
+		Code string `uri:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/invite/accept] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if group, err := invite.Accept(user, uri.Code); err != nil {
+		inviteStatus(c, "/group/invite/accept", err)
+	} else {
+		c.JSON(http.StatusOK, group)
+	}
+}
@@ -0,0 +1,172 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	DefaultMembersLimit = 50
+	MaxMembersLimit     = 100
+)
+
+type MembersResponseItem struct {
+	Identifier  string `json:"ident"`
+	DisplayName string `json:"display_name"`
+	Role        string `json:"role"`
+	JoinedAt    int64  `json:"joined_at"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+type MembersResponse struct {
+	Members []MembersResponseItem `json:"members"`
+	Next    *string               `json:"next_cursor"`
+}
+
+type memberRow struct {
+	model.UserRoom
+
+	User model.UserAccount
+	Rank int64  `alias:"rank"`
+	Name string `alias:"name"`
+}
+
+// avatarURL gets the path of the profile picture of a user.
+func avatarURL(ident string) string {
+	return globals.Opts.BasePath + "/user/profile_picture/" + ident
+}
+
+// countMembers gets the number of members of a group.
+func countMembers(group int64) (int64, error) {
+	var dest struct {
+		Count int64 `alias:"count"`
+	}
+
+	err := SELECT(COUNT(UserRoom.UserID).AS("count")).FROM(UserRoom).WHERE(UserRoom.RoomID.EQ(Int64(group))).Query(globals.Database, &dest)
+	return dest.Count, err
+}
+
+// Members godoc
+// @Summary Gets group members
+// @Description Gets the members of a group, owners first, then moderators, then everyone else, each by display name. Members who have blocked the user are left out, as are those whose privacy settings hide them from the user.
+// @Tags group
+// @Produce json
+// @Success 200 {object} MembersResponse
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Param id     path  int64  true  "Group ID"
+// @Param limit  query int64  false "Max number of members to retrieve (<= 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Router /group/members/{id} [get]
+func Members(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Limit  int64  `form:"limit"`
+		Cursor string `form:"cursor"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = DefaultMembersLimit
+	} else if request.Limit > MaxMembersLimit {
+		request.Limit = MaxMembersLimit
+	}
+
+	caller, ok := expectVisible(c, "/group/members", uri.ID)
+	if !ok {
+		return
+	}
+
+	role, err := member.Role(caller, uri.ID)
+	if err != nil {
+		fmt.Printf("[/group/members] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	rank := IntExp(CASE(UserRoom.Role).
+		WHEN(String(member.RoleOwner)).THEN(Int(0)).
+		WHEN(String(member.RoleModerator)).THEN(Int(1)).
+		ELSE(Int(2)))
+	name := LOWER(UserAccount.DisplayName)
+
+	cond := UserRoom.RoomID.EQ(Int64(uri.ID)).AND(NOT(EXISTS(
+		SELECT(UserBlock.UserID).FROM(UserBlock).WHERE(
+			UserBlock.UserID.EQ(UserRoom.UserID).AND(UserBlock.BlockUserID.EQ(Int64(caller))),
+		),
+	)))
+
+	if role != member.RoleModerator && role != member.RoleOwner {
+		visible := UserAccount.Privacy.EQ(String(member.PrivacyPublic)).OR(UserAccount.ID.EQ(Int64(caller)))
+		if role != "" {
+			visible = visible.OR(UserAccount.Privacy.EQ(String(member.PrivacyMembers)))
+		}
+
+		cond = cond.AND(visible)
+	}
+
+	if request.Cursor != "" {
+		var pos memberCursor
+		if err := decodeCursor(request.Cursor, &pos); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		cond = cond.AND(
+			rank.GT(Int64(pos.Rank)).OR(rank.EQ(Int64(pos.Rank)).AND(
+				name.GT(String(pos.Name)).OR(name.EQ(String(pos.Name)).AND(UserAccount.ID.GT(Int64(pos.ID)))),
+			)),
+		)
+	}
+
+	stmt := SELECT(
+		UserRoom.UserID, UserRoom.RoomID, UserRoom.Role, UserRoom.JoinedAt,
+		UserAccount.ID, UserAccount.Identifier, UserAccount.DisplayName,
+		rank.AS("rank"), name.AS("name"),
+	).FROM(
+		UserRoom.INNER_JOIN(UserAccount, UserRoom.UserID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(rank.ASC(), name.ASC(), UserAccount.ID.ASC()).LIMIT(request.Limit)
+
+	var dest []memberRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/members] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := MembersResponse{
+		Members: lo.Map(dest, func(x memberRow, _ int) MembersResponseItem {
+			return MembersResponseItem{x.User.Identifier, x.User.DisplayName, x.Role, x.JoinedAt, avatarURL(x.User.Identifier)}
+		}),
+	}
+
+	if int64(len(dest)) == request.Limit {
+		last := dest[len(dest)-1]
+		response.Next = lo.ToPtr(memberCursor{last.Rank, last.Name, last.User.ID}.String())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
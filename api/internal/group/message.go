@@ -0,0 +1,207 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+type EditRequest struct {
+	MessageID int64  `json:"message_id"`
+	Contents  string `json:"contents"`
+}
+
+// Edit godoc
+// @Summary Edit message
+// @Description Replaces the contents of a message, keeping the previous contents as a revision. Authors may not edit while muted, banned, or after leaving the group.
+// @Tags group
+// @Produce json
+// @Consume json
+// @Success 200 {object} chat.Message
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body EditRequest true "Message to edit"
+// @Router /group/edit [post]
+func Edit(c *gin.Context) {
+	var request EditRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/edit] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch msg, err := chat.Edit(user, request.MessageID, request.Contents); err {
+	default:
+		fmt.Printf("[/group/edit] Failed to edit message: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrInvalid, moderation.ErrRejected:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden, chat.ErrMuted, chat.ErrNotMember:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.JSON(http.StatusOK, msg)
+	}
+}
+
+type DeleteRequest struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// Delete godoc
+// @Summary Delete message
+// @Description Deletes a message, keeping the previous contents as a revision. Authors may not delete while muted, banned, or after leaving the group.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body DeleteRequest true "Message to delete"
+// @Router /group/delete [post]
+func Delete(c *gin.Context) {
+	var request DeleteRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/delete] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch _, err := chat.Delete(user, request.MessageID); err {
+	default:
+		fmt.Printf("[/group/delete] Failed to delete message: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden, chat.ErrMuted, chat.ErrNotMember:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type RevisionsResponseItem struct {
+	Identifier string `json:"user_ident"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+}
+
+// Revisions godoc
+// @Summary Gets message revisions
+// @Description Gets the previous contents of an edited message in descending order. Only the author and moderators of the group may see them, and they are gone once the message is deleted.
+// @Tags group
+// @Produce json
+// @Success 200 {array} RevisionsResponseItem
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Param message_id path int64 true "Message ID"
+// @Router /group/revisions/{message_id} [get]
+func Revisions(c *gin.Context) {
+	var uri struct {
+		MessageID int64 `uri:"message_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var msg model.RoomMessage
+	if err := SELECT(RoomMessage.RoomID, RoomMessage.UserID, RoomMessage.DeletedAt).FROM(RoomMessage).WHERE(RoomMessage.ID.EQ(Int64(uri.MessageID))).Query(globals.Database, &msg); err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/group/revisions] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Deleting a message withdraws its earlier versions too
+	if msg.DeletedAt != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	caller, ok := expectVisible(c, "/group/revisions", msg.RoomID)
+	if !ok {
+		return
+	}
+
+	if caller == 0 {
+		c.Status(http.StatusForbidden)
+		return
+	} else if msg.UserID == nil || *msg.UserID != caller {
+		if ok, err := member.IsModerator(caller, msg.RoomID); err != nil {
+			fmt.Printf("[/group/revisions] Error querying database: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		} else if !ok {
+			c.Status(http.StatusForbidden)
+			return
+		}
+	}
+
+	stmt := SELECT(
+		RoomMessageRevision.AllColumns,
+		UserAccount.Identifier,
+	).FROM(
+		RoomMessageRevision.INNER_JOIN(UserAccount, RoomMessageRevision.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		RoomMessageRevision.MessageID.EQ(Int64(uri.MessageID)),
+	).ORDER_BY(RoomMessageRevision.ID.DESC())
+
+	var dest []struct {
+		model.RoomMessageRevision
+
+		User model.UserAccount
+	}
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/revisions] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x struct {
+		model.RoomMessageRevision
+		User model.UserAccount
+	}, _ int) RevisionsResponseItem {
+		return RevisionsResponseItem{
+			x.User.Identifier,
+			x.Contents,
+			x.Iat,
+		}
+	}))
+}
@@ -0,0 +1,272 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+	"github.com/tetrago/motmot/api/internal/retention"
+)
+
+// expectModerator gets the user making a request, responding with an error if
+// they do not moderate the group. The second return value is false if a
+// response has already been written.
+func expectModerator(c *gin.Context, route string, group int64) (model.UserAccount, bool) {
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return user, false
+	}
+
+	if ok, err := member.IsModerator(user.ID, group); err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return user, false
+	} else if !ok {
+		c.Status(http.StatusForbidden)
+		return user, false
+	}
+
+	return user, true
+}
+
+type FiltersResponseItem struct {
+	ID     int64  `json:"id"`
+	Word   string `json:"word"`
+	Action string `json:"action"`
+}
+
+// Filters godoc
+// @Summary Gets group word filters
+// @Description Gets the moderation rules specific to a group
+// @Tags group
+// @Produce json
+// @Success 200 {array} FiltersResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Group ID"
+// @Router /group/filters/{id} [get]
+func Filters(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/filters", uri.ID); !ok {
+		return
+	}
+
+	var dest []model.ModerationRule
+	stmt := SELECT(ModerationRule.AllColumns).FROM(ModerationRule).WHERE(ModerationRule.RoomID.EQ(Int64(uri.ID))).ORDER_BY(ModerationRule.Word.ASC())
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/filters] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x model.ModerationRule, _ int) FiltersResponseItem {
+		return FiltersResponseItem{x.ID, x.Word, x.Action}
+	}))
+}
+
+type FilterRequest struct {
+	GroupID int64  `json:"group_id"`
+	Word    string `json:"word"`
+	Action  string `json:"action"`
+}
+
+// Filter godoc
+// @Summary Add group word filter
+// @Description Adds a moderation rule to a group. The action is one of mask, flag, or reject.
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {object} FiltersResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body FilterRequest true "Rule to add"
+// @Router /group/filter [post]
+func Filter(c *gin.Context) {
+	var request FilterRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	request.Word = strings.TrimSpace(request.Word)
+	if len(request.Word) == 0 || len(request.Word) > 64 || !lo.Contains(moderation.Actions, request.Action) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/filter", request.GroupID); !ok {
+		return
+	}
+
+	var dest model.ModerationRule
+	stmt := ModerationRule.INSERT(ModerationRule.RoomID, ModerationRule.Word, ModerationRule.Action).MODEL(model.ModerationRule{
+		RoomID: &request.GroupID,
+		Word:   request.Word,
+		Action: request.Action,
+	}).RETURNING(ModerationRule.AllColumns)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil {
+		fmt.Printf("[/group/filter] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	} else {
+		c.JSON(http.StatusOK, FiltersResponseItem{dest.ID, dest.Word, dest.Action})
+	}
+}
+
+type UnfilterRequest struct {
+	GroupID int64 `json:"group_id"`
+	ID      int64 `json:"id"`
+}
+
+// Unfilter godoc
+// @Summary Remove group word filter
+// @Description Removes a moderation rule from a group
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body UnfilterRequest true "Rule to remove"
+// @Router /group/unfilter [post]
+func Unfilter(c *gin.Context) {
+	var request UnfilterRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/unfilter", request.GroupID); !ok {
+		return
+	}
+
+	var dest model.ModerationRule
+	stmt := ModerationRule.DELETE().WHERE(
+		ModerationRule.ID.EQ(Int64(request.ID)).AND(ModerationRule.RoomID.EQ(Int64(request.GroupID))),
+	).RETURNING(ModerationRule.AllColumns)
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+	} else if err != nil {
+		fmt.Printf("[/group/unfilter] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	} else {
+		c.Status(http.StatusOK)
+	}
+}
+
+type RetentionRequest struct {
+	GroupID int64  `json:"group_id"`
+	Keep    string `json:"keep"`
+	Days    int64  `json:"days"`
+}
+
+// Retention godoc
+// @Summary Set group retention
+// @Description Sets how long the messages of a group are kept before being purged: forever, a number of days, or until the end of term. Keeping them as "default" follows the site-wide setting.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body RetentionRequest true "Retention policy"
+// @Router /group/retention [post]
+func Retention(c *gin.Context) {
+	var request RetentionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/retention", request.GroupID); !ok {
+		return
+	}
+
+	var policy *retention.Policy
+	if request.Keep != "default" {
+		policy = &retention.Policy{Keep: request.Keep, Days: request.Days}
+	}
+
+	switch err := retention.Set(request.GroupID, policy); err {
+	default:
+		fmt.Printf("[/group/retention] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case retention.ErrPolicy:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type PostingRequest struct {
+	GroupID          int64 `json:"group_id"`
+	SlowMode         int64 `json:"slow_mode"`
+	AnnouncementOnly bool  `json:"announcement_only"`
+	MemberDays       int64 `json:"member_days"`
+}
+
+// Posting godoc
+// @Summary Set group posting policy
+// @Description Restricts who may post to a group and how often. Slow mode makes each user wait a number of seconds (<= 21600) between messages, announcement-only lets only moderators post, and a number of days (<= 365) may be required between joining and posting. Zero turns a restriction off. Moderators are exempt.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body PostingRequest true "Posting policy"
+// @Router /group/posting [post]
+func Posting(c *gin.Context) {
+	var request PostingRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/posting", request.GroupID); !ok {
+		return
+	}
+
+	switch err := chat.SetPolicy(request.GroupID, chat.Policy{SlowMode: request.SlowMode, AnnouncementOnly: request.AnnouncementOnly, MemberDays: request.MemberDays}); err {
+	default:
+		fmt.Printf("[/group/posting] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrPolicy:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
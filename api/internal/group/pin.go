@@ -0,0 +1,186 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+type PinsResponseItem struct {
+	ID         int64  `json:"message_id"`
+	Identifier string `json:"user_ident"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+	PinnedBy   string `json:"pinned_by"`
+	PinnedAt   int64  `json:"pinned_at"`
+}
+
+// Pins godoc
+// @Summary Gets pinned messages
+// @Description Gets the pinned messages of a group, most recently pinned first, leaving out those by blocked users
+// @Tags group
+// @Produce json
+// @Success 200 {array} PinsResponseItem
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Group ID"
+// @Router /group/pins/{id} [get]
+func Pins(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	caller, ok := expectVisible(c, "/group/pins", uri.ID)
+	if !ok {
+		return
+	}
+
+	pinner := UserAccount.AS("pinner")
+
+	stmt := SELECT(
+		MessagePin.AllColumns,
+		RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat,
+		UserAccount.ID, UserAccount.Identifier,
+		pinner.ID, pinner.Identifier,
+	).FROM(
+		MessagePin.
+			INNER_JOIN(RoomMessage, MessagePin.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)).
+			INNER_JOIN(pinner, MessagePin.UserID.EQ(pinner.ID)),
+	).WHERE(
+		MessagePin.RoomID.EQ(Int64(uri.ID)).AND(member.Unblocked(caller, RoomMessage.UserID)),
+	).ORDER_BY(MessagePin.Iat.DESC())
+
+	var dest []struct {
+		model.MessagePin
+
+		Message model.RoomMessage
+		Author  model.UserAccount
+		Pinner  model.UserAccount `alias:"pinner"`
+	}
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/pins] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x struct {
+		model.MessagePin
+		Message model.RoomMessage
+		Author  model.UserAccount
+		Pinner  model.UserAccount `alias:"pinner"`
+	}, _ int) PinsResponseItem {
+		return PinsResponseItem{
+			x.Message.ID,
+			x.Author.Identifier,
+			x.Message.Contents,
+			x.Message.Iat,
+			x.Pinner.Identifier,
+			x.Iat,
+		}
+	}))
+}
+
+type PinRequest struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// Pin godoc
+// @Summary Pin message
+// @Description Pins a message to its group
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body PinRequest true "Message to pin"
+// @Router /group/pin [post]
+func Pin(c *gin.Context) {
+	var request PinRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/pin] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Pin(user, request.MessageID); err {
+	default:
+		fmt.Printf("[/group/pin] Failed to pin message: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrPinLimit:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+// Unpin godoc
+// @Summary Unpin message
+// @Description Unpins a message from its group
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body PinRequest true "Message to unpin"
+// @Router /group/unpin [post]
+func Unpin(c *gin.Context) {
+	var request PinRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/unpin] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Unpin(user, request.MessageID); err {
+	default:
+		fmt.Printf("[/group/unpin] Failed to unpin message: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,112 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+type VoteRequest struct {
+	MessageID int64   `json:"message_id"`
+	Options   []int64 `json:"options"`
+}
+
+// Vote godoc
+// @Summary Vote on poll
+// @Description Replaces the user's votes on a poll with the given options. Voting for no options retracts the vote.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Param request body VoteRequest true "Poll and options to vote for"
+// @Router /group/vote [post]
+func Vote(c *gin.Context) {
+	var request VoteRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/vote] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Vote(user, request.MessageID, request.Options); err {
+	default:
+		fmt.Printf("[/group/vote] Failed to vote: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrVote:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrNotMember:
+		c.Status(http.StatusForbidden)
+	case chat.ErrPollClosed:
+		c.Status(http.StatusConflict)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type ClosePollRequest struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// ClosePoll godoc
+// @Summary Close poll
+// @Description Stops a poll from accepting votes. Only the author or a moderator of the group may close a poll.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Param request body ClosePollRequest true "Poll to close"
+// @Router /group/closepoll [post]
+func ClosePoll(c *gin.Context) {
+	var request ClosePollRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/closepoll] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.ClosePoll(user, request.MessageID); err {
+	default:
+		fmt.Printf("[/group/closepoll] Failed to close poll: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case chat.ErrPollClosed:
+		c.Status(http.StatusConflict)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,141 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// callerID gets the ID of the user making a request on a public route, or zero
+// if the request is not authenticated.
+func callerID(c *gin.Context) (int64, error) {
+	token := auth.OptionalToken(c)
+	if token == nil {
+		return 0, nil
+	}
+
+	var dest model.UserAccount
+	stmt := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier())))
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	} else {
+		return dest.ID, nil
+	}
+}
+
+// expectVisible gets the ID of the user making a request, which is zero if they
+// are anonymous, responding with an error unless they can read a group. The
+// second return value is false if a response has already been written.
+func expectVisible(c *gin.Context, route string, group int64) (int64, bool) {
+	caller, err := callerID(c)
+	if err != nil {
+		fmt.Printf("[%s] Error querying database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return 0, false
+	}
+
+	if ok, err := member.CanView(caller, group); err != nil {
+		fmt.Printf("[%s] Error querying database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return 0, false
+	} else if !ok {
+		c.Status(http.StatusForbidden)
+		return 0, false
+	}
+
+	return caller, true
+}
+
+type ReactRequest struct {
+	MessageID int64  `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// React godoc
+// @Summary React to message
+// @Description Adds a reaction to a message
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body ReactRequest true "Reaction to add"
+// @Router /group/react [post]
+func React(c *gin.Context) {
+	var request ReactRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/react] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.React(user, request.MessageID, request.Emoji); err {
+	default:
+		fmt.Printf("[/group/react] Failed to add reaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrEmoji:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+// Unreact godoc
+// @Summary Remove reaction
+// @Description Removes a reaction from a message
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body ReactRequest true "Reaction to remove"
+// @Router /group/unreact [post]
+func Unreact(c *gin.Context) {
+	var request ReactRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/unreact] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Unreact(user, request.MessageID, request.Emoji); err {
+	default:
+		fmt.Printf("[/group/unreact] Failed to remove reaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
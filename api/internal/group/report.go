@@ -0,0 +1,329 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+type ReportRequest struct {
+	MessageID int64  `json:"message_id"`
+	Reason    string `json:"reason"`
+}
+
+// Report godoc
+// @Summary Report message
+// @Description Reports a message to the moderators of its group. A user may only have one open report on a message.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Failure 500
+// @Param request body ReportRequest true "Message to report"
+// @Router /group/report [post]
+func Report(c *gin.Context) {
+	var request ReportRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/report] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Report(&user.ID, request.MessageID, request.Reason); err {
+	default:
+		fmt.Printf("[/group/report] Failed to report message: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrReason:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case chat.ErrReported:
+		c.Status(http.StatusConflict)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type ReportsResponseItem struct {
+	ID         int64  `json:"report_id"`
+	GroupID    int64  `json:"group_id"`
+	GroupName  string `json:"group_name"`
+	MessageID  int64  `json:"message_id"`
+	Identifier string `json:"user_ident"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+	DeletedAt  *int64 `json:"deleted_at"`
+	Reporter   string `json:"reporter_ident"`
+	Reason     string `json:"reason"`
+	ReportedAt int64  `json:"reported_at"`
+}
+
+// Reports godoc
+// @Summary Gets open reports
+// @Description Gets the open reports in every group the user moderates, oldest first. Reports raised automatically by moderation rules have an empty reporter.
+// @Tags group
+// @Produce json
+// @Success 200 {array} ReportsResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param group_id query int64 false "Only include reports from this group"
+// @Param limit    query int64 true  "Maximum number of reports"
+// @Param after    query int64 false "Only include reports after this report ID"
+// @Router /group/reports [get]
+func Reports(c *gin.Context) {
+	var request struct {
+		GroupID int64 `form:"group_id"`
+		Limit   int64 `form:"limit" binding:"required"`
+		After   int64 `form:"after"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit > 20 {
+		request.Limit = 20
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/reports] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	cond := MessageReport.ResolvedAt.IS_NULL().
+		AND(MessageReport.ID.GT(Int64(request.After))).
+		AND(MessageReport.RoomID.IN(
+			SELECT(UserRoom.RoomID).FROM(UserRoom).WHERE(
+				UserRoom.UserID.EQ(Int64(user.ID)).AND(member.Moderates()),
+			),
+		))
+
+	if request.GroupID != 0 {
+		cond = cond.AND(MessageReport.RoomID.EQ(Int64(request.GroupID)))
+	}
+
+	reporter := UserAccount.AS("reporter")
+
+	stmt := SELECT(
+		MessageReport.AllColumns,
+		Room.ID, Room.Name,
+		RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.DeletedAt,
+		UserAccount.ID, UserAccount.Identifier,
+		reporter.ID, reporter.Identifier,
+	).FROM(
+		MessageReport.
+			INNER_JOIN(Room, MessageReport.RoomID.EQ(Room.ID)).
+			INNER_JOIN(RoomMessage, MessageReport.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)).
+			LEFT_JOIN(reporter, MessageReport.UserID.EQ(reporter.ID)),
+	).WHERE(cond).ORDER_BY(MessageReport.ID.ASC()).LIMIT(request.Limit)
+
+	type reportRow struct {
+		model.MessageReport
+
+		Room     model.Room
+		Message  model.RoomMessage
+		Author   model.UserAccount
+		Reporter *model.UserAccount `alias:"reporter"`
+	}
+
+	var dest []reportRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/reports] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x reportRow, _ int) ReportsResponseItem {
+		var reporter string
+		if x.Reporter != nil {
+			reporter = x.Reporter.Identifier
+		}
+
+		return ReportsResponseItem{
+			x.ID,
+			x.Room.ID,
+			x.Room.Name,
+			x.Message.ID,
+			x.Author.Identifier,
+			x.Message.Contents,
+			x.Message.Iat,
+			x.Message.DeletedAt,
+			reporter,
+			x.Reason,
+			x.Iat,
+		}
+	}))
+}
+
+type ResolveRequest struct {
+	ReportID int64  `json:"report_id"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason"`
+	Duration int64  `json:"duration"`
+}
+
+// Resolve godoc
+// @Summary Resolve report
+// @Description Resolves a report along with every other open report on the same message. The action is one of dismiss, delete, warn, mute, or ban. Mutes and bans last for the duration in seconds, or until lifted if zero.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body ResolveRequest true "Report resolution"
+// @Router /group/resolve [post]
+func Resolve(c *gin.Context) {
+	var request ResolveRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/resolve] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := chat.Resolve(user, request.ReportID, chat.Resolution{Action: request.Action, Reason: request.Reason, Duration: request.Duration}); err {
+	default:
+		fmt.Printf("[/group/resolve] Failed to resolve report: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrAction, chat.ErrReason:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+type LogResponseItem struct {
+	ID         int64  `json:"id"`
+	Moderator  string `json:"moderator_ident"`
+	Identifier string `json:"user_ident"`
+	MessageID  *int64 `json:"message_id"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason"`
+	IssuedAt   int64  `json:"iat"`
+}
+
+// Log godoc
+// @Summary Gets moderation log
+// @Description Gets the actions taken by the moderators of a group, most recent first
+// @Tags group
+// @Produce json
+// @Success 200 {array} LogResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id     path  int64 true  "Group ID"
+// @Param limit  query int64 true  "Maximum number of entries"
+// @Param before query int64 false "Only include entries before this entry ID"
+// @Router /group/log/{id} [get]
+func Log(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Limit  int64 `form:"limit" binding:"required"`
+		Before int64 `form:"before"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit > 20 {
+		request.Limit = 20
+	}
+
+	if _, ok := expectModerator(c, "/group/log", uri.ID); !ok {
+		return
+	}
+
+	cond := ModerationLog.RoomID.EQ(Int64(uri.ID))
+	if request.Before != 0 {
+		cond = cond.AND(ModerationLog.ID.LT(Int64(request.Before)))
+	}
+
+	moderator := UserAccount.AS("moderator")
+
+	stmt := SELECT(
+		ModerationLog.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+		moderator.ID, moderator.Identifier,
+	).FROM(
+		ModerationLog.
+			INNER_JOIN(UserAccount, ModerationLog.UserID.EQ(UserAccount.ID)).
+			INNER_JOIN(moderator, ModerationLog.ModeratorID.EQ(moderator.ID)),
+	).WHERE(cond).ORDER_BY(ModerationLog.ID.DESC()).LIMIT(request.Limit)
+
+	type logRow struct {
+		model.ModerationLog
+
+		User      model.UserAccount
+		Moderator model.UserAccount `alias:"moderator"`
+	}
+
+	var dest []logRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/log] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x logRow, _ int) LogResponseItem {
+		return LogResponseItem{
+			x.ID,
+			x.Moderator.Identifier,
+			x.User.Identifier,
+			x.MessageID,
+			x.Action,
+			x.Reason,
+			x.Iat,
+		}
+	}))
+}
@@ -0,0 +1,255 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+type SanctionRequest struct {
+	GroupID    int64  `json:"group_id"`
+	Identifier string `json:"user_ident"`
+	Reason     string `json:"reason"`
+
+	// Only used by mutes and bans
+	Duration int64 `json:"duration"`
+}
+
+type LiftRequest struct {
+	GroupID    int64  `json:"group_id"`
+	Identifier string `json:"user_ident"`
+	Kind       string `json:"kind"`
+	Reason     string `json:"reason"`
+}
+
+// act takes a measure against the user named in a request on behalf of the
+// user making it.
+func act(c *gin.Context, route string, group int64, ident string, measure chat.Measure) {
+	token := auth.ExpectToken(c)
+
+	var users []model.UserAccount
+	stmt := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(
+		UserAccount.Identifier.IN(String(token.UserIdentifier()), String(ident)),
+	)
+
+	if err := stmt.Query(globals.Database, &users); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	moderator, ok := lo.Find(users, func(x model.UserAccount) bool { return x.Identifier == token.UserIdentifier() })
+	if !ok {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	user, ok := lo.Find(users, func(x model.UserAccount) bool { return x.Identifier == ident })
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	switch err := chat.Act(moderator, group, user, measure); err {
+	default:
+		fmt.Printf("[%s] Failed to act on user: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrNotFound, chat.ErrNotMember, chat.ErrAction, chat.ErrReason:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
+
+// Kick godoc
+// @Summary Kick group member
+// @Description Removes a member from a group and closes their sockets on it. They may rejoin. Moderators may only be kicked by the owner.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body SanctionRequest true "Member to kick"
+// @Router /group/kick [post]
+func Kick(c *gin.Context) {
+	var request SanctionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	act(c, "/group/kick", request.GroupID, request.Identifier, chat.Measure{Action: chat.ActionKick, Reason: request.Reason})
+}
+
+// Ban godoc
+// @Summary Ban user from group
+// @Description Removes a user from a group, closes their sockets on it, and prevents them from rejoining for the duration in seconds, or until lifted if zero
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body SanctionRequest true "User to ban"
+// @Router /group/ban [post]
+func Ban(c *gin.Context) {
+	var request SanctionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	act(c, "/group/ban", request.GroupID, request.Identifier, chat.Measure{Action: chat.ActionBan, Reason: request.Reason, Duration: request.Duration})
+}
+
+// Mute godoc
+// @Summary Mute group member
+// @Description Prevents a member from posting to a group for the duration in seconds, or until lifted if zero
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body SanctionRequest true "Member to mute"
+// @Router /group/mute [post]
+func Mute(c *gin.Context) {
+	var request SanctionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	act(c, "/group/mute", request.GroupID, request.Identifier, chat.Measure{Action: chat.ActionMute, Reason: request.Reason, Duration: request.Duration})
+}
+
+// Lift godoc
+// @Summary Lift group sanction
+// @Description Lifts every mute or ban in effect on a user in a group. The kind is one of mute or ban.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body LiftRequest true "Sanction to lift"
+// @Router /group/lift [post]
+func Lift(c *gin.Context) {
+	var request LiftRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var action string
+	switch request.Kind {
+	case member.SanctionMute:
+		action = chat.ActionUnmute
+	case member.SanctionBan:
+		action = chat.ActionUnban
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	act(c, "/group/lift", request.GroupID, request.Identifier, chat.Measure{Action: action, Reason: request.Reason})
+}
+
+type SanctionsResponseItem struct {
+	ID         int64  `json:"id"`
+	Identifier string `json:"user_ident"`
+	Moderator  string `json:"moderator_ident"`
+	Kind       string `json:"kind"`
+	Reason     string `json:"reason"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  *int64 `json:"expires_at"`
+}
+
+// Sanctions godoc
+// @Summary Gets group sanctions
+// @Description Gets the mutes and bans in effect in a group, most recent first
+// @Tags group
+// @Produce json
+// @Success 200 {array} SanctionsResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param id path int64 true "Group ID"
+// @Router /group/sanctions/{id} [get]
+func Sanctions(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := expectModerator(c, "/group/sanctions", uri.ID); !ok {
+		return
+	}
+
+	moderator := UserAccount.AS("moderator")
+	now := Int64(time.Now().Unix())
+
+	stmt := SELECT(
+		RoomSanction.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+		moderator.ID, moderator.Identifier,
+	).FROM(
+		RoomSanction.
+			INNER_JOIN(UserAccount, RoomSanction.UserID.EQ(UserAccount.ID)).
+			INNER_JOIN(moderator, RoomSanction.ModeratorID.EQ(moderator.ID)),
+	).WHERE(
+		RoomSanction.RoomID.EQ(Int64(uri.ID)).
+			AND(RoomSanction.LiftedAt.IS_NULL()).
+			AND(RoomSanction.ExpiresAt.IS_NULL().OR(RoomSanction.ExpiresAt.GT(now))),
+	).ORDER_BY(RoomSanction.ID.DESC())
+
+	type sanctionRow struct {
+		model.RoomSanction
+
+		User      model.UserAccount
+		Moderator model.UserAccount `alias:"moderator"`
+	}
+
+	var dest []sanctionRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/sanctions] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x sanctionRow, _ int) SanctionsResponseItem {
+		return SanctionsResponseItem{
+			ID:         x.ID,
+			Identifier: x.User.Identifier,
+			Moderator:  x.Moderator.Identifier,
+			Kind:       x.Kind,
+			Reason:     x.Reason,
+			IssuedAt:   x.Iat,
+			ExpiresAt:  x.ExpiresAt,
+		}
+	}))
+}
@@ -0,0 +1,228 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/moderation"
+	"github.com/tetrago/motmot/api/internal/schedule"
+)
+
+type ScheduledResponseItem struct {
+	ID          int64  `json:"id"`
+	GroupID     int64  `json:"group_id"`
+	Identifier  string `json:"user_ident"`
+	Contents    string `json:"contents"`
+	SendAt      int64  `json:"send_at"`
+	RepeatEvery *int64 `json:"repeat_every,omitempty"`
+	RepeatUntil *int64 `json:"repeat_until,omitempty"`
+}
+
+func newScheduledResponseItem(x model.ScheduledMessage, ident string) ScheduledResponseItem {
+	return ScheduledResponseItem{x.ID, x.RoomID, ident, x.Contents, x.SendAt, x.RepeatEvery, x.RepeatUntil}
+}
+
+// scheduleStatus writes the response for an error from the schedule package.
+func scheduleStatus(c *gin.Context, route string, err error) {
+	switch err {
+	default:
+		fmt.Printf("[%s] Failed to schedule message: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+	case schedule.ErrNotFound, schedule.ErrTime, schedule.ErrRepeat, schedule.ErrLimit, chat.ErrInvalid, moderation.ErrRejected:
+		c.Status(http.StatusBadRequest)
+	case chat.ErrNotMember, chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	}
+}
+
+type ScheduleRequest struct {
+	GroupID     int64  `json:"group_id"`
+	Contents    string `json:"contents"`
+	SendAt      int64  `json:"send_at"`
+	RepeatEvery *int64 `json:"repeat_every"`
+	RepeatUntil *int64 `json:"repeat_until"`
+}
+
+// Schedule godoc
+// @Summary Schedule message
+// @Description Schedules a message to be posted to a group at a future UTC time. Recurring messages repeat every given number of seconds (at least an hour), optionally until a UTC time.
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {object} ScheduledResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body ScheduleRequest true "Message to schedule"
+// @Router /group/schedule [post]
+func Schedule(c *gin.Context) {
+	var request ScheduleRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/schedule] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	plan := schedule.Plan{Contents: request.Contents, SendAt: request.SendAt, RepeatEvery: request.RepeatEvery, RepeatUntil: request.RepeatUntil}
+	if dest, err := schedule.Create(user, request.GroupID, plan); err != nil {
+		scheduleStatus(c, "/group/schedule", err)
+	} else {
+		c.JSON(http.StatusOK, newScheduledResponseItem(dest, user.Identifier))
+	}
+}
+
+// Scheduled godoc
+// @Summary Gets scheduled messages
+// @Description Gets the messages the user has waiting to be posted, soonest first. Moderators may instead get those of every member of a group, so as to cancel them.
+// @Tags group
+// @Produce json
+// @Success 200 {array} ScheduledResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param group_id query int64 false "Only include messages for this group"
+// @Param all      query bool  false "Include messages from every member of the group; requires group_id and moderator rights"
+// @Router /group/scheduled [get]
+func Scheduled(c *gin.Context) {
+	var request struct {
+		GroupID *int64 `form:"group_id"`
+		All     bool   `form:"all"`
+	}
+
+	if err := c.BindQuery(&request); err != nil || (request.All && request.GroupID == nil) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/scheduled] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	var dest []schedule.Item
+	var err error
+
+	if request.All {
+		dest, err = schedule.PendingInGroup(user.ID, *request.GroupID)
+	} else {
+		dest, err = schedule.Pending(user.ID, request.GroupID)
+	}
+
+	switch err {
+	default:
+		fmt.Printf("[/group/scheduled] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case chat.ErrForbidden:
+		c.Status(http.StatusForbidden)
+	case nil:
+		c.JSON(http.StatusOK, lo.Map(dest, func(x schedule.Item, _ int) ScheduledResponseItem {
+			return newScheduledResponseItem(x.ScheduledMessage, x.User.Identifier)
+		}))
+	}
+}
+
+type RescheduleRequest struct {
+	ID          int64  `json:"id"`
+	Contents    string `json:"contents"`
+	SendAt      int64  `json:"send_at"`
+	RepeatEvery *int64 `json:"repeat_every"`
+	RepeatUntil *int64 `json:"repeat_until"`
+}
+
+// Reschedule godoc
+// @Summary Edit scheduled message
+// @Description Replaces the contents and timing of a message the user has waiting to be posted
+// @Tags group
+// @Consume json
+// @Produce json
+// @Success 200 {object} ScheduledResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body RescheduleRequest true "Updated message"
+// @Router /group/reschedule [post]
+func Reschedule(c *gin.Context) {
+	var request RescheduleRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/reschedule] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	plan := schedule.Plan{Contents: request.Contents, SendAt: request.SendAt, RepeatEvery: request.RepeatEvery, RepeatUntil: request.RepeatUntil}
+	if dest, err := schedule.Update(user, request.ID, plan); err != nil {
+		scheduleStatus(c, "/group/reschedule", err)
+	} else {
+		c.JSON(http.StatusOK, newScheduledResponseItem(dest, user.Identifier))
+	}
+}
+
+type UnscheduleRequest struct {
+	ID int64 `json:"id"`
+}
+
+// Unschedule godoc
+// @Summary Cancel scheduled message
+// @Description Cancels a message waiting to be posted. Moderators may cancel messages scheduled by anyone in their groups.
+// @Tags group
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Param request body UnscheduleRequest true "Message to cancel"
+// @Router /group/unschedule [post]
+func Unschedule(c *gin.Context) {
+	var request UnscheduleRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/group/unschedule] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := schedule.Cancel(user, request.ID); err != nil {
+		scheduleStatus(c, "/group/unschedule", err)
+	} else {
+		c.Status(http.StatusOK)
+	}
+}
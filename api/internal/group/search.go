@@ -0,0 +1,219 @@
+package group
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	sortRank   = "rank"
+	sortRecent = "recent"
+)
+
+// searchDocument must match the expression of the room_message_search index.
+const searchDocument = "to_tsvector('english', room_message.contents)"
+
+const searchQuery = "websearch_to_tsquery('english', #query)"
+
+// Matches are delimited in snippets by control characters so that the contents
+// can be escaped before the delimiters are replaced with markup.
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+var snippetMarkup = strings.NewReplacer(snippetStart, "<mark>", snippetStop, "</mark>")
+
+// highlight escapes a snippet as HTML, wrapping each match in a mark element.
+func highlight(snippet string) string {
+	return snippetMarkup.Replace(html.EscapeString(snippet))
+}
+
+type SearchResponseItem struct {
+	ID         int64           `json:"message_id"`
+	Identifier string          `json:"user_ident"`
+	Contents   string          `json:"contents"`
+	Snippet    string          `json:"snippet"`
+	IssuedAt   int64           `json:"iat"`
+	ParentID   *int64          `json:"parent_id,omitempty"`
+	Reactions  []chat.Reaction `json:"reactions"`
+
+	Attachments []chat.Attachment `json:"attachments,omitempty"`
+}
+
+type SearchResponse struct {
+	Results []SearchResponseItem `json:"results"`
+	Next    *string              `json:"next_cursor"`
+}
+
+// Search godoc
+// @Summary Searchs messages
+// @Description Searches the messages of a group using web search syntax: words, "quoted phrases", `or`, and `-` to exclude. Messages by blocked users are left out. Snippets are HTML with each match wrapped in a mark element.
+// @Tags group
+// @Produce json
+// @Success 200 {object} SearchResponse
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Param id     path  int64  true  "Group ID"
+// @Param query  query string true  "Text to search messages for"
+// @Param author query string false "Only include messages from this user identifier"
+// @Param from   query int64  false "Only include messages sent at or after this UTC time"
+// @Param to     query int64  false "Only include messages sent at or before this UTC time"
+// @Param sort   query string false "Order of results, either rank (default) or recent"
+// @Param limit  query int64  true  "Max number of messages to retreive (1 to 20)"
+// @Param cursor query string false "Cursor from a previous search to continue from"
+// @Router /group/search/{id} [get]
+func Search(c *gin.Context) {
+	var uri struct {
+		ID int64 `uri:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	caller, ok := expectVisible(c, "/group/search", uri.ID)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Query  string `form:"query" binding:"required"`
+		Author string `form:"author"`
+		From   int64  `form:"from"`
+		To     int64  `form:"to"`
+		Sort   string `form:"sort"`
+		Limit  int64  `form:"limit" binding:"required,min=1,max=20"`
+		Cursor string `form:"cursor"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Sort == "" {
+		request.Sort = sortRank
+	} else if request.Sort != sortRank && request.Sort != sortRecent {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	args := RawArgs{"#query": request.Query}
+	rank := RawFloat("ts_rank("+searchDocument+", "+searchQuery+")", args)
+	snippet := RawString("ts_headline('english', room_message.contents, "+searchQuery+", #options)", RawArgs{
+		"#query":   request.Query,
+		"#options": "StartSel=" + snippetStart + ", StopSel=" + snippetStop,
+	})
+
+	cond := RoomMessage.RoomID.EQ(Int64(uri.ID)).
+		AND(RoomMessage.DeletedAt.IS_NULL()).
+		AND(member.Unblocked(caller, RoomMessage.UserID)).
+		AND(RawBool(searchDocument+" @@ "+searchQuery, args))
+
+	if request.Author != "" {
+		cond = cond.AND(UserAccount.Identifier.EQ(String(request.Author)))
+	}
+
+	if request.From != 0 {
+		cond = cond.AND(RoomMessage.Iat.GT_EQ(Int64(request.From)))
+	}
+
+	if request.To != 0 {
+		cond = cond.AND(RoomMessage.Iat.LT_EQ(Int64(request.To)))
+	}
+
+	if request.Cursor != "" {
+		cur, err := parseCursor(request.Cursor)
+		if err != nil || (cur.Rank != nil) != (request.Sort == sortRank) {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		if request.Sort == sortRank {
+			// Compare as real, the type ts_rank returns, so the cursor's own rank is matched exactly
+			r := RawFloat("#rank::real", RawArgs{"#rank": *cur.Rank})
+			cond = cond.AND(rank.LT(r).OR(rank.EQ(r).AND(RoomMessage.ID.LT(Int64(cur.ID)))))
+		} else {
+			cond = cond.AND(RoomMessage.Iat.LT(Int64(cur.Iat)).OR(RoomMessage.Iat.EQ(Int64(cur.Iat)).AND(RoomMessage.ID.LT(Int64(cur.ID)))))
+		}
+	}
+
+	order := []OrderByClause{RoomMessage.Iat.DESC(), RoomMessage.ID.DESC()}
+	if request.Sort == sortRank {
+		order = []OrderByClause{rank.DESC(), RoomMessage.ID.DESC()}
+	}
+
+	stmt := SELECT(
+		RoomMessage.ID, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.ParentID,
+		UserAccount.Identifier,
+		rank.AS("rank"),
+		snippet.AS("snippet"),
+	).FROM(
+		RoomMessage.INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(order...).LIMIT(request.Limit)
+
+	type searchRow struct {
+		model.RoomMessage
+
+		User    model.UserAccount
+		Rank    float64 `alias:"rank"`
+		Snippet string  `alias:"snippet"`
+	}
+
+	var dest []searchRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/search] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	extras, err := loadExtras(c, lo.Map(dest, func(x searchRow, _ int) messageRow { return messageRow{x.RoomMessage, x.User} }))
+	if err != nil {
+		fmt.Printf("[/group/search] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := SearchResponse{
+		Results: lo.Map(dest, func(x searchRow, _ int) SearchResponseItem {
+			return SearchResponseItem{
+				x.ID,
+				x.User.Identifier,
+				x.Contents,
+				highlight(x.Snippet),
+				x.Iat,
+				x.ParentID,
+				extras.reactionsOf(x.ID),
+				extras.attachments[x.ID],
+			}
+		}),
+	}
+
+	if int64(len(dest)) == request.Limit {
+		last := dest[len(dest)-1]
+		next := cursor{Iat: last.Iat, ID: last.ID}
+		if request.Sort == sortRank {
+			next.Rank = &last.Rank
+		}
+
+		response.Next = lo.ToPtr(next.String())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
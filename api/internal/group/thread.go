@@ -0,0 +1,164 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+type replySummary struct {
+	ParentID int64 `alias:"parent_id"`
+	Count    int64 `alias:"count"`
+	Last     int64 `alias:"last"`
+}
+
+// replySummaries gets the reply count and latest reply time of each message
+// with at least one reply, not counting those by users the viewer has blocked.
+func replySummaries(ids []int64, viewer int64) (map[int64]replySummary, error) {
+	if len(ids) == 0 {
+		return map[int64]replySummary{}, nil
+	}
+
+	stmt := SELECT(
+		RoomMessage.ParentID.AS("parent_id"),
+		COUNT(RoomMessage.ID).AS("count"),
+		MAX(RoomMessage.Iat).AS("last"),
+	).FROM(RoomMessage).WHERE(
+		RoomMessage.ParentID.IN(lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })...).
+			AND(RoomMessage.DeletedAt.IS_NULL()).
+			AND(member.Unblocked(viewer, RoomMessage.UserID)),
+	).GROUP_BY(RoomMessage.ParentID)
+
+	var dest []replySummary
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return lo.KeyBy(dest, func(x replySummary) int64 { return x.ParentID }), nil
+}
+
+type ThreadResponse struct {
+	Messages []HistoryResponseItem `json:"messages"`
+
+	// Next continues towards newer replies
+	Next *string `json:"next_cursor"`
+}
+
+// Thread godoc
+// @Summary Gets thread replies
+// @Description Gets replies to a message in ascending order, leaving out those by blocked users
+// @Tags group
+// @Produce json
+// @Success 200 {object} ThreadResponse
+// @Failure 400
+// @Failure 403
+// @Failure 500
+// @Param message_id path  int64  true  "Parent message ID"
+// @Param limit      query int64  true  "Max number of replies to retreive (1 to 20)"
+// @Param after      query string false "Cursor; gets replies newer than it"
+// @Router /group/thread/{message_id} [get]
+func Thread(c *gin.Context) {
+	var uri struct {
+		MessageID int64 `uri:"message_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Limit int64  `form:"limit" binding:"required,min=1,max=20"`
+		After string `form:"after"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	cond := RoomMessage.ParentID.EQ(Int64(uri.MessageID))
+	if request.After != "" {
+		pos, err := parseCursor(request.After)
+		if err != nil || pos.Rank != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		iat, id := Int64(pos.Iat), Int64(pos.ID)
+		cond = cond.AND(RoomMessage.Iat.GT(iat).OR(RoomMessage.Iat.EQ(iat).AND(RoomMessage.ID.GT(id))))
+	}
+
+	var parent model.RoomMessage
+	if err := SELECT(RoomMessage.ID, RoomMessage.RoomID).FROM(RoomMessage).WHERE(RoomMessage.ID.EQ(Int64(uri.MessageID))).Query(globals.Database, &parent); err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/group/thread] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	caller, ok := expectVisible(c, "/group/thread", parent.RoomID)
+	if !ok {
+		return
+	}
+
+	stmt := SELECT(
+		RoomMessage.ID, RoomMessage.Kind, RoomMessage.Payload, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.EditedAt, RoomMessage.DeletedAt, RoomMessage.ParentID,
+		UserAccount.Identifier,
+	).FROM(
+		RoomMessage.LEFT_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		cond.AND(member.Unblocked(caller, RoomMessage.UserID)),
+	).ORDER_BY(RoomMessage.Iat.ASC(), RoomMessage.ID.ASC()).LIMIT(request.Limit + 1)
+
+	var dest []messageRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/group/thread] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	var response ThreadResponse
+	if int64(len(dest)) > request.Limit {
+		dest = dest[:request.Limit]
+		response.Next = cursorOf(dest[len(dest)-1].RoomMessage)
+	}
+
+	extras, err := loadExtras(c, dest)
+	if err != nil {
+		fmt.Printf("[/group/thread] Error querying database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response.Messages = lo.Map(dest, func(x messageRow, _ int) HistoryResponseItem {
+		return HistoryResponseItem{
+			ID:          x.ID,
+			Identifier:  x.User.Identifier,
+			Kind:        x.Kind,
+			Contents:    x.Contents,
+			IssuedAt:    x.Iat,
+			EditedAt:    x.EditedAt,
+			DeletedAt:   x.DeletedAt,
+			ParentID:    x.ParentID,
+			Reactions:   extras.reactionsOf(x.ID),
+			Attachments: extras.attachments[x.ID],
+			Poll:        extras.pollOf(x.ID),
+			System:      chat.SystemOf(x.RoomMessage),
+		}
+	})
+
+	c.JSON(http.StatusOK, response)
+}
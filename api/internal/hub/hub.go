@@ -0,0 +1,153 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// Subscriber receives every event published to the group it is subscribed to,
+// except those published by users it blocks.
+type Subscriber struct {
+	UserID  int64
+	GroupID int64
+
+	events chan any
+	closed chan struct{}
+	once   sync.Once
+	blocks map[int64]bool
+}
+
+var mutex sync.RWMutex
+var groups = make(map[int64][]*Subscriber)
+var users = make(map[int64][]*Subscriber)
+
+// Subscribe registers a new subscriber for a user on a group, given the users
+// they have blocked. The subscriber must be released with Unsubscribe once the
+// connection is closed.
+func Subscribe(user int64, group int64, blocks []int64) *Subscriber {
+	s := &Subscriber{
+		UserID:  user,
+		GroupID: group,
+		events:  make(chan any, 64),
+		closed:  make(chan struct{}),
+		blocks:  lo.SliceToMap(blocks, func(x int64) (int64, bool) { return x, true }),
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	groups[group] = append(groups[group], s)
+	users[user] = append(users[user], s)
+	return s
+}
+
+func remove(subscribers map[int64][]*Subscriber, key int64, s *Subscriber) {
+	if _, index, ok := lo.FindIndexOf(subscribers[key], func(x *Subscriber) bool { return x == s }); !ok {
+		panic("Channel bus mismatch!")
+	} else {
+		subscribers[key] = append(subscribers[key][:index], subscribers[key][index+1:]...)
+	}
+
+	if len(subscribers[key]) == 0 {
+		delete(subscribers, key)
+	}
+}
+
+func Unsubscribe(s *Subscriber) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	remove(groups, s.GroupID, s)
+	remove(users, s.UserID, s)
+}
+
+func (s *Subscriber) Events() <-chan any {
+	return s.events
+}
+
+// Closed is closed once the subscriber has been disconnected from its group,
+// after which its connection should be dropped.
+func (s *Subscriber) Closed() <-chan struct{} {
+	return s.closed
+}
+
+func (s *Subscriber) deliver(event any) {
+	select {
+	case s.events <- event:
+	default:
+		// Slow consumers drop events rather than stalling the whole group
+	}
+}
+
+// Publish sends an event to every subscriber of a group except the one given,
+// which may be nil.
+func Publish(group int64, event any, except *Subscriber) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, s := range groups[group] {
+		if s != except {
+			s.deliver(event)
+		}
+	}
+}
+
+// PublishFrom sends an event caused by a user to every subscriber of a group who
+// has not blocked them, except the one given, which may be nil.
+func PublishFrom(group int64, author int64, event any, except *Subscriber) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, s := range groups[group] {
+		if s != except && !s.blocks[author] {
+			s.deliver(event)
+		}
+	}
+}
+
+// Notify sends an event to every subscriber of a user, regardless of the group
+// they are subscribed to.
+func Notify(user int64, event any) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, s := range users[user] {
+		s.deliver(event)
+	}
+}
+
+// Disconnect closes every subscriber of a user on a group, such as when they
+// are removed from it by a moderator.
+func Disconnect(user int64, group int64) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, s := range users[user] {
+		if s.GroupID == group {
+			s.once.Do(func() { close(s.closed) })
+		}
+	}
+}
+
+// Block stops every subscriber of a user from receiving events published from
+// another user.
+func Block(user int64, other int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, s := range users[user] {
+		s.blocks[other] = true
+	}
+}
+
+// Unblock resumes the delivery of events published from another user to every
+// subscriber of a user.
+func Unblock(user int64, other int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, s := range users[user] {
+		delete(s.blocks, other)
+	}
+}
@@ -0,0 +1,211 @@
+package inbox
+
+import (
+	"sort"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+// Kinds of notification.
+const (
+	KindMention    = "mention"
+	KindReply      = "reply"
+	KindDirect     = "direct"
+	KindModeration = "moderation"
+	KindInvite     = "invite"
+)
+
+const EventNotification = "notification.created"
+
+// MaxBodyLength caps the text carried by a notification, in characters.
+const MaxBodyLength = 512
+
+// Notice is a notification to be delivered to a user.
+type Notice struct {
+	UserID    int64
+	Kind      string
+	GroupID   *int64
+	MessageID *int64
+
+	// Actor is the user whose action raised the notification, if it is not
+	// anonymous
+	Actor *model.UserAccount
+
+	Body string
+}
+
+// Item is a notification in the inbox of a user. New items are also sent to
+// every socket the user has open.
+type Item struct {
+	Type      string `json:"type,omitempty"`
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	GroupID   *int64 `json:"group_id,omitempty"`
+	MessageID *int64 `json:"message_id,omitempty"`
+	Actor     string `json:"actor_ident,omitempty"`
+	Body      string `json:"body"`
+	IssuedAt  int64  `json:"iat"`
+	ReadAt    *int64 `json:"read_at"`
+
+	user int64
+}
+
+// ItemOf converts a stored notification sent by an actor, given by identifier.
+func ItemOf(n model.Notification, actor string) Item {
+	return Item{
+		user:      n.UserID,
+		ID:        n.ID,
+		Kind:      n.Kind,
+		GroupID:   n.RoomID,
+		MessageID: n.MessageID,
+		Actor:     actor,
+		Body:      n.Body,
+		IssuedAt:  n.Iat,
+		ReadAt:    n.ReadAt,
+	}
+}
+
+// filter drops the notices silenced by the notification preferences their
+// users have for the groups they were raised in. Users are always told of
+// actions taken against them by moderators.
+func filter(tx qrm.DB, notices []Notice, now int64) ([]Notice, error) {
+	exempt := func(x Notice) bool { return x.GroupID == nil || x.Kind == KindModeration }
+
+	groups := lo.GroupBy(lo.Reject(notices, func(x Notice, _ int) bool { return exempt(x) }), func(x Notice) int64 { return *x.GroupID })
+	prefs := make(map[int64]map[int64]member.Preference, len(groups))
+
+	for group, xs := range groups {
+		p, err := member.Preferences(tx, group, lo.Map(xs, func(x Notice, _ int) int64 { return x.UserID }))
+		if err != nil {
+			return nil, err
+		}
+
+		prefs[group] = p
+	}
+
+	return lo.Filter(notices, func(x Notice, _ int) bool {
+		return exempt(x) || prefs[*x.GroupID][x.UserID].Allows(x.Kind == KindMention, now)
+	}), nil
+}
+
+// Deliver stores notices in the inboxes of their users as part of a
+// transaction, unless their users have silenced them. The items are returned to
+// be pushed once the transaction has been committed.
+func Deliver(tx qrm.DB, notices ...Notice) ([]Item, error) {
+	now := time.Now().Unix()
+
+	notices, err := filter(tx, notices, now)
+	if err != nil || len(notices) == 0 {
+		return nil, err
+	}
+
+	rows := lo.Map(notices, func(x Notice, _ int) model.Notification {
+		if body := []rune(x.Body); len(body) > MaxBodyLength {
+			x.Body = string(body[:MaxBodyLength])
+		}
+
+		row := model.Notification{
+			UserID:    x.UserID,
+			Kind:      x.Kind,
+			RoomID:    x.GroupID,
+			MessageID: x.MessageID,
+			Body:      x.Body,
+			Iat:       now,
+		}
+
+		if x.Actor != nil {
+			row.ActorID = &x.Actor.ID
+		}
+
+		return row
+	})
+
+	var dest []model.Notification
+	stmt := Notification.INSERT(
+		Notification.UserID,
+		Notification.Kind,
+		Notification.RoomID,
+		Notification.MessageID,
+		Notification.ActorID,
+		Notification.Body,
+		Notification.Iat,
+	).MODELS(rows).RETURNING(Notification.AllColumns)
+
+	if err := stmt.Query(tx, &dest); err != nil {
+		return nil, err
+	}
+
+	// IDs are assigned in the order the rows were inserted
+	sort.Slice(dest, func(i, j int) bool { return dest[i].ID < dest[j].ID })
+
+	return lo.Map(dest, func(x model.Notification, i int) Item {
+		var actor string
+		if notices[i].Actor != nil {
+			actor = notices[i].Actor.Identifier
+		}
+
+		item := ItemOf(x, actor)
+		item.Type = EventNotification
+		return item
+	}), nil
+}
+
+// Push sends delivered items to the sockets of their users.
+func Push(items []Item) {
+	for _, item := range items {
+		hub.Notify(item.user, item)
+	}
+}
+
+// Send delivers notices outside of any transaction and pushes them.
+func Send(notices ...Notice) error {
+	items, err := Deliver(globals.Database, notices...)
+	if err != nil {
+		return err
+	}
+
+	Push(items)
+	return nil
+}
+
+// MarkRead marks notifications in the inbox of a user as read.
+func MarkRead(user int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stmt := Notification.UPDATE(Notification.ReadAt).SET(Int64(time.Now().Unix())).WHERE(
+		Notification.UserID.EQ(Int64(user)).
+			AND(Notification.ReadAt.IS_NULL()).
+			AND(Notification.ID.IN(lo.Map(ids, func(x int64, _ int) Expression { return Int64(x) })...)),
+	)
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// MarkAllRead marks every notification in the inbox of a user as read.
+func MarkAllRead(user int64) error {
+	stmt := Notification.UPDATE(Notification.ReadAt).SET(Int64(time.Now().Unix())).WHERE(
+		Notification.UserID.EQ(Int64(user)).AND(Notification.ReadAt.IS_NULL()),
+	)
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// Withdraw removes every notification of a message being deleted, since they
+// carry its contents.
+func Withdraw(tx qrm.DB, message int64) error {
+	_, err := Notification.DELETE().WHERE(Notification.MessageID.EQ(Int64(message))).Exec(tx)
+	return err
+}
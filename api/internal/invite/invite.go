@@ -0,0 +1,370 @@
+package invite
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/crypt"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+// CodeLength is the number of characters in an invite code.
+const CodeLength = 12
+
+var (
+	ErrNotFound   = errors.New("invite not found")
+	ErrExpired    = errors.New("invite expired or used up")
+	ErrForbidden  = errors.New("not permitted to manage invites")
+	ErrInvalid    = errors.New("invalid invite limits")
+	ErrVisibility = errors.New("group does not accept invites")
+	ErrBanned     = errors.New("banned from group")
+)
+
+// Limits restrict how long and how often an invite may be used.
+type Limits struct {
+	ExpiresAt *int64
+	MaxUses   *int64
+}
+
+// Usage is an invite along with its creator and how many current members
+// joined through it.
+type Usage struct {
+	model.RoomInvite
+
+	Creator model.UserAccount `alias:"creator"`
+	Members int64             `alias:"members"`
+}
+
+// mayInvite ensures a user may create invites to a group. Any member may invite
+// others to an unlisted group, but only moderators to a private one. Public
+// groups are joined freely and direct conversations not at all.
+func mayInvite(user int64, group int64) error {
+	var room model.Room
+	if err := SELECT(Room.Kind, Room.Visibility).FROM(Room).WHERE(Room.ID.EQ(Int64(group))).Query(globals.Database, &room); err == qrm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if room.Kind == member.KindDirect || room.Visibility == member.VisibilityPublic {
+		return ErrVisibility
+	}
+
+	role, err := member.Role(user, group)
+	if err != nil {
+		return err
+	} else if role == "" || (room.Visibility == member.VisibilityPrivate && role == member.RoleMember) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// generate makes a random code not used by any other invite.
+func generate() (string, error) {
+	for {
+		code, err := crypt.GenerateBase64(CodeLength)
+		if err != nil {
+			return "", err
+		}
+
+		var dest model.RoomInvite
+		if err := SELECT(RoomInvite.ID).FROM(RoomInvite).WHERE(RoomInvite.Code.EQ(String(code))).Query(globals.Database, &dest); err == qrm.ErrNoRows {
+			return code, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+// Create makes a new invite to a group.
+func Create(user int64, group int64, limits Limits) (model.RoomInvite, error) {
+	now := time.Now().Unix()
+	if (limits.ExpiresAt != nil && *limits.ExpiresAt <= now) || (limits.MaxUses != nil && *limits.MaxUses <= 0) {
+		return model.RoomInvite{}, ErrInvalid
+	}
+
+	if err := mayInvite(user, group); err != nil {
+		return model.RoomInvite{}, err
+	}
+
+	code, err := generate()
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+
+	var dest model.RoomInvite
+	stmt := RoomInvite.INSERT(
+		RoomInvite.Code,
+		RoomInvite.RoomID,
+		RoomInvite.UserID,
+		RoomInvite.MaxUses,
+		RoomInvite.Iat,
+		RoomInvite.ExpiresAt,
+	).MODEL(model.RoomInvite{
+		Code:      code,
+		RoomID:    group,
+		UserID:    user,
+		MaxUses:   limits.MaxUses,
+		Iat:       now,
+		ExpiresAt: limits.ExpiresAt,
+	}).RETURNING(RoomInvite.AllColumns)
+
+	err = stmt.Query(globals.Database, &dest)
+	return dest, err
+}
+
+// List gets the invites to a group, newest first. Moderators see every invite,
+// while other members only see their own.
+func List(user int64, group int64) ([]Usage, error) {
+	cond := RoomInvite.RoomID.EQ(Int64(group))
+
+	if ok, err := member.IsModerator(user, group); err != nil {
+		return nil, err
+	} else if !ok {
+		cond = cond.AND(RoomInvite.UserID.EQ(Int64(user)))
+	}
+
+	creator := UserAccount.AS("creator")
+
+	var dest []Usage
+	stmt := SELECT(
+		RoomInvite.AllColumns,
+		creator.ID, creator.Identifier,
+		COUNT(UserRoom.UserID).AS("members"),
+	).FROM(
+		RoomInvite.
+			INNER_JOIN(creator, RoomInvite.UserID.EQ(creator.ID)).
+			LEFT_JOIN(UserRoom, UserRoom.InviteID.EQ(RoomInvite.ID)),
+	).WHERE(cond).GROUP_BY(RoomInvite.ID, creator.ID).ORDER_BY(RoomInvite.ID.DESC())
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// expectManaged gets an invite that a user may manage, being either its
+// creator or a moderator of its group. The second return value is true if the
+// user is a moderator.
+func expectManaged(user int64, id int64) (model.RoomInvite, bool, error) {
+	var dest model.RoomInvite
+	if err := SELECT(RoomInvite.AllColumns).FROM(RoomInvite).WHERE(RoomInvite.ID.EQ(Int64(id))).Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return dest, false, ErrNotFound
+	} else if err != nil {
+		return dest, false, err
+	}
+
+	ok, err := member.IsModerator(user, dest.RoomID)
+	if err != nil {
+		return dest, false, err
+	} else if !ok && dest.UserID != user {
+		return dest, false, ErrForbidden
+	}
+
+	return dest, ok, nil
+}
+
+// Members gets the current members of a group who joined through an invite.
+// Only moderators may trace an invite.
+func Members(user int64, id int64) ([]model.UserAccount, error) {
+	if _, ok, err := expectManaged(user, id); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrForbidden
+	}
+
+	var dest []model.UserAccount
+	stmt := SELECT(UserAccount.ID, UserAccount.Identifier, UserAccount.DisplayName).FROM(
+		UserRoom.INNER_JOIN(UserAccount, UserRoom.UserID.EQ(UserAccount.ID)),
+	).WHERE(UserRoom.InviteID.EQ(Int64(id))).ORDER_BY(UserAccount.DisplayName.ASC())
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Revoke stops an invite from being used, returning the number of members
+// removed. Moderators may also remove the members who joined through it, such
+// as when a link has leaked; those with a moderating role are kept. Removals are
+// recorded in the moderation log and announced like kicks, and the sockets of
+// removed members on the group are closed.
+func Revoke(user model.UserAccount, id int64, remove bool) (int64, error) {
+	invite, moderator, err := expectManaged(user.ID, id)
+	if err != nil {
+		return 0, err
+	} else if remove && !moderator {
+		return 0, ErrForbidden
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	if invite.RevokedAt == nil {
+		upd := RoomInvite.UPDATE(RoomInvite.RevokedAt).SET(Int64(time.Now().Unix())).WHERE(RoomInvite.ID.EQ(Int64(id)))
+		if _, err := upd.Exec(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	var removed []model.UserAccount
+	var announcements []chat.Message
+	if remove {
+		var memberships []model.UserRoom
+		del := UserRoom.DELETE().WHERE(
+			UserRoom.InviteID.EQ(Int64(id)).AND(UserRoom.Role.EQ(String(member.RoleMember))),
+		).RETURNING(UserRoom.UserID, UserRoom.RoomID)
+
+		if err := del.Query(tx, &memberships); err != nil && err != qrm.ErrNoRows {
+			return 0, err
+		}
+
+		if len(memberships) > 0 {
+			ids := lo.Map(memberships, func(x model.UserRoom, _ int) Expression { return Int64(x.UserID) })
+			if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.ID.IN(ids...)).Query(tx, &removed); err != nil {
+				return 0, err
+			}
+		}
+
+		for _, x := range removed {
+			if _, err := moderation.Record(tx, model.ModerationLog{
+				RoomID:      invite.RoomID,
+				ModeratorID: user.ID,
+				UserID:      x.ID,
+				Action:      chat.ActionKick,
+				Reason:      "Joined through revoked invite " + invite.Code,
+			}); err != nil {
+				return 0, err
+			}
+
+			msg, err := chat.Announce(tx, invite.RoomID, chat.SystemEvent{
+				Event:      chat.SystemModeration,
+				Identifier: x.Identifier,
+				Moderator:  user.Identifier,
+				Action:     chat.ActionKick,
+			})
+
+			if err != nil {
+				return 0, err
+			}
+
+			announcements = append(announcements, msg)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, msg := range announcements {
+		hub.Publish(invite.RoomID, msg, nil)
+	}
+
+	for _, x := range removed {
+		hub.Disconnect(x.ID, invite.RoomID)
+	}
+
+	return int64(len(removed)), nil
+}
+
+// Accept joins a user to the group of an invite, returning the group, announces
+// it to the group, and tells the creator of the invite. Users who are already
+// members are left as they are, without using up the invite.
+func Accept(user model.UserAccount, code string) (int64, error) {
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	var invite model.RoomInvite
+	stmt := SELECT(RoomInvite.AllColumns).FROM(RoomInvite).WHERE(RoomInvite.Code.EQ(String(code))).FOR(UPDATE())
+
+	if err := stmt.Query(tx, &invite); err == qrm.ErrNoRows {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	if role, err := member.Role(user.ID, invite.RoomID); err != nil {
+		return 0, err
+	} else if role != "" {
+		return invite.RoomID, nil
+	}
+
+	now := time.Now().Unix()
+	if invite.RevokedAt != nil || (invite.ExpiresAt != nil && *invite.ExpiresAt <= now) || (invite.MaxUses != nil && invite.Uses >= *invite.MaxUses) {
+		return 0, ErrExpired
+	}
+
+	if sanction, err := member.Active(user.ID, invite.RoomID, member.SanctionBan); err != nil {
+		return 0, err
+	} else if sanction != nil {
+		return 0, ErrBanned
+	}
+
+	upd := RoomInvite.UPDATE(RoomInvite.Uses).SET(RoomInvite.Uses.ADD(Int64(1))).WHERE(RoomInvite.ID.EQ(Int64(invite.ID)))
+	if _, err := upd.Exec(tx); err != nil {
+		return 0, err
+	}
+
+	ins := UserRoom.INSERT(UserRoom.UserID, UserRoom.RoomID, UserRoom.InviteID).MODEL(model.UserRoom{
+		UserID:   user.ID,
+		RoomID:   invite.RoomID,
+		InviteID: &invite.ID,
+	})
+
+	if _, err := ins.Exec(tx); err != nil {
+		return 0, err
+	}
+
+	msg, err := chat.Announce(tx, invite.RoomID, chat.SystemEvent{Event: chat.SystemJoined, Identifier: user.Identifier})
+	if err != nil {
+		return 0, err
+	}
+
+	// The creator of the invite is told who joined with it
+	var items []inbox.Item
+	if blocked, err := member.Blocked(invite.UserID, user.ID); err != nil {
+		return 0, err
+	} else if !blocked {
+		items, err = inbox.Deliver(tx, inbox.Notice{
+			UserID:  invite.UserID,
+			Kind:    inbox.KindInvite,
+			GroupID: &invite.RoomID,
+			Actor:   &user,
+			Body:    invite.Code,
+		})
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	hub.Publish(invite.RoomID, msg, nil)
+	inbox.Push(items)
+	return invite.RoomID, nil
+}
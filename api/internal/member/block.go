@@ -0,0 +1,46 @@
+package member
+
+import (
+	"errors"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// ErrBlocked is returned when users are kept apart because one of them has
+// blocked another.
+var ErrBlocked = errors.New("blocked by or blocking a participant")
+
+// Blocks gets the users a user has blocked.
+func Blocks(user int64) ([]int64, error) {
+	var dest []model.UserBlock
+	stmt := SELECT(UserBlock.BlockUserID).FROM(UserBlock).WHERE(UserBlock.UserID.EQ(Int64(user)))
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return lo.Map(dest, func(x model.UserBlock, _ int) int64 { return x.BlockUserID }), nil
+}
+
+// Unblocked matches rows whose author, given as a column, has not been blocked
+// by a user. Anonymous users, given as zero, have blocked nobody, and rows
+// without an author are always matched.
+func Unblocked(user int64, author ColumnInteger) BoolExpression {
+	return author.IS_NULL().OR(author.NOT_IN(
+		SELECT(UserBlock.BlockUserID).FROM(UserBlock).WHERE(UserBlock.UserID.EQ(Int64(user))),
+	))
+}
+
+// NotBlocking matches rows whose user, given as a column, has not blocked an
+// author.
+func NotBlocking(user ColumnInteger, author int64) BoolExpression {
+	return user.NOT_IN(
+		SELECT(UserBlock.UserID).FROM(UserBlock).WHERE(UserBlock.BlockUserID.EQ(Int64(author))),
+	)
+}
@@ -0,0 +1,53 @@
+package member
+
+import (
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+const (
+	RoleMember    = "member"
+	RoleModerator = "moderator"
+	RoleOwner     = "owner"
+)
+
+// Who may see which groups a user belongs to, both in member lists and on
+// their profile. Users who only show themselves to members are seen by those
+// sharing a group with them, while hidden users are only seen by moderators.
+const (
+	PrivacyPublic  = "public"
+	PrivacyMembers = "members"
+	PrivacyHidden  = "hidden"
+)
+
+// Role returns the role of a user in a group, or an empty string if the user
+// is not a member.
+func Role(user int64, group int64) (string, error) {
+	var dest model.UserRoom
+	stmt := SELECT(UserRoom.Role).FROM(UserRoom).WHERE(
+		UserRoom.UserID.EQ(Int64(user)).AND(UserRoom.RoomID.EQ(Int64(group))),
+	)
+
+	if err := stmt.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	} else {
+		return dest.Role, nil
+	}
+}
+
+// IsModerator reports whether a user moderates a group, which owners do too.
+func IsModerator(user int64, group int64) (bool, error) {
+	role, err := Role(user, group)
+	return role == RoleModerator || role == RoleOwner, err
+}
+
+// Moderates matches the memberships of users who moderate their group.
+func Moderates() BoolExpression {
+	return UserRoom.Role.IN(String(RoleModerator), String(RoleOwner))
+}
@@ -0,0 +1,101 @@
+package member
+
+import (
+	"errors"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// How much a member wants to be notified of in a group.
+const (
+	NotifyAll      = "all"
+	NotifyMentions = "mentions"
+	NotifyNothing  = "nothing"
+)
+
+var NotifyLevels = []string{NotifyAll, NotifyMentions, NotifyNothing}
+
+var (
+	ErrPreference = errors.New("invalid notification preference")
+	ErrNotMember  = errors.New("not a member of group")
+)
+
+// Preference is the notification preference of a member of a group. Users who
+// are not members are notified of everything.
+type Preference struct {
+	Level string `json:"level"`
+
+	// MutedUntil silences the group entirely until the time, whatever the level
+	MutedUntil *int64 `json:"muted_until,omitempty"`
+}
+
+var defaultPreference = Preference{Level: NotifyAll}
+
+// PreferenceOf gets the notification preference stored on a membership.
+func PreferenceOf(membership model.UserRoom) Preference {
+	return Preference{membership.Notify, membership.MutedUntil}
+}
+
+// Muted reports whether the preference silences every notification at a time.
+func (p Preference) Muted(now int64) bool {
+	return p.Level == NotifyNothing || (p.MutedUntil != nil && *p.MutedUntil > now)
+}
+
+// Allows reports whether the preference lets a notification through at a time,
+// given whether it mentions the member.
+func (p Preference) Allows(mention bool, now int64) bool {
+	return !p.Muted(now) && (mention || p.Level == NotifyAll)
+}
+
+// Preferences gets the notification preferences of users in a group, including
+// those who are not members.
+func Preferences(db qrm.DB, group int64, users []int64) (map[int64]Preference, error) {
+	prefs := lo.SliceToMap(users, func(x int64) (int64, Preference) { return x, defaultPreference })
+	if len(users) == 0 {
+		return prefs, nil
+	}
+
+	var dest []model.UserRoom
+	stmt := SELECT(UserRoom.UserID, UserRoom.Notify, UserRoom.MutedUntil).FROM(UserRoom).WHERE(
+		UserRoom.RoomID.EQ(Int64(group)).
+			AND(UserRoom.UserID.IN(lo.Map(users, func(x int64, _ int) Expression { return Int64(x) })...)),
+	)
+
+	if err := stmt.Query(db, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	for _, membership := range dest {
+		prefs[membership.UserID] = PreferenceOf(membership)
+	}
+
+	return prefs, nil
+}
+
+// SetPreference changes the notification preference of a member of a group.
+func SetPreference(user int64, group int64, pref Preference) error {
+	if !lo.Contains(NotifyLevels, pref.Level) || (pref.MutedUntil != nil && *pref.MutedUntil < 0) {
+		return ErrPreference
+	}
+
+	stmt := UserRoom.UPDATE(UserRoom.Notify, UserRoom.MutedUntil).
+		MODEL(model.UserRoom{Notify: pref.Level, MutedUntil: pref.MutedUntil}).
+		WHERE(UserRoom.UserID.EQ(Int64(user)).AND(UserRoom.RoomID.EQ(Int64(group))))
+
+	res, err := stmt.Exec(globals.Database)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotMember
+	}
+
+	return nil
+}
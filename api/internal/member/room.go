@@ -0,0 +1,94 @@
+package member
+
+import (
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// Kinds of group.
+const (
+	KindCourse = "course"
+	KindDirect = "direct"
+	KindGroup  = "group"
+)
+
+// Who may find a group. Unlisted groups are left out of listings but may be
+// read by anyone, while private groups may only be read by their members.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+// Kind gets the kind of a group, or an empty string if it does not exist.
+func Kind(group int64) (string, error) {
+	var dest model.Room
+	if err := SELECT(Room.Kind).FROM(Room).WHERE(Room.ID.EQ(Int64(group))).Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	} else {
+		return dest.Kind, nil
+	}
+}
+
+// CanView reports whether a user may read the messages of a group. Private
+// groups, including direct conversations, are only visible to their members,
+// and users banned from a group may not read it whatever its visibility. A user
+// of zero is anonymous.
+func CanView(user int64, group int64) (bool, error) {
+	var dest model.Room
+	if err := SELECT(Room.Visibility).FROM(Room).WHERE(Room.ID.EQ(Int64(group))).Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if user != 0 {
+		if ban, err := Active(user, group, SanctionBan); err != nil || ban != nil {
+			return false, err
+		}
+	}
+
+	if dest.Visibility != VisibilityPrivate {
+		return true, nil
+	}
+
+	role, err := Role(user, group)
+	return role != "", err
+}
+
+// Members gets the IDs of every member of a group.
+func Members(group int64) ([]int64, error) {
+	var dest []model.UserRoom
+	if err := SELECT(UserRoom.UserID).FROM(UserRoom).WHERE(UserRoom.RoomID.EQ(Int64(group))).Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return lo.Map(dest, func(x model.UserRoom, _ int) int64 { return x.UserID }), nil
+}
+
+// Blocked reports whether any of the users has blocked another of them.
+func Blocked(users ...int64) (bool, error) {
+	if len(users) < 2 {
+		return false, nil
+	}
+
+	in := lo.Map(users, func(x int64, _ int) Expression { return Int64(x) })
+
+	var dest []model.UserBlock
+	stmt := SELECT(UserBlock.UserID).FROM(UserBlock).WHERE(
+		UserBlock.UserID.IN(in...).AND(UserBlock.BlockUserID.IN(in...)).AND(UserBlock.UserID.NOT_EQ(UserBlock.BlockUserID)),
+	).LIMIT(1)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return false, err
+	}
+
+	return len(dest) > 0, nil
+}
@@ -0,0 +1,100 @@
+package member
+
+import (
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// Kinds of sanction placed on a user in a group.
+const (
+	SanctionMute = "mute"
+	SanctionBan  = "ban"
+)
+
+// Sanction places a restriction on a user in a group. A ban also removes the
+// user from the group. Sanctions without an expiry last until lifted.
+func Sanction(tx qrm.DB, sanction model.RoomSanction) (model.RoomSanction, error) {
+	sanction.Iat = time.Now().Unix()
+
+	var dest model.RoomSanction
+	stmt := RoomSanction.INSERT(
+		RoomSanction.RoomID,
+		RoomSanction.UserID,
+		RoomSanction.ModeratorID,
+		RoomSanction.Kind,
+		RoomSanction.Reason,
+		RoomSanction.Iat,
+		RoomSanction.ExpiresAt,
+	).MODEL(sanction).RETURNING(RoomSanction.AllColumns)
+
+	if err := stmt.Query(tx, &dest); err != nil {
+		return model.RoomSanction{}, err
+	}
+
+	if sanction.Kind == SanctionBan {
+		del := UserRoom.DELETE().WHERE(
+			UserRoom.UserID.EQ(Int64(sanction.UserID)).AND(UserRoom.RoomID.EQ(Int64(sanction.RoomID))),
+		)
+
+		if _, err := del.Exec(tx); err != nil {
+			return model.RoomSanction{}, err
+		}
+	}
+
+	return dest, nil
+}
+
+// Active gets the longest lasting sanction of one of the given kinds in effect
+// on a user in a group, or nil if there is none.
+func Active(user int64, group int64, kinds ...string) (*model.RoomSanction, error) {
+	now := time.Now().Unix()
+
+	var dest []model.RoomSanction
+	stmt := SELECT(RoomSanction.AllColumns).FROM(RoomSanction).WHERE(
+		RoomSanction.UserID.EQ(Int64(user)).
+			AND(RoomSanction.RoomID.EQ(Int64(group))).
+			AND(RoomSanction.Kind.IN(lo.Map(kinds, func(x string, _ int) Expression { return String(x) })...)).
+			AND(RoomSanction.LiftedAt.IS_NULL()).
+			AND(RoomSanction.ExpiresAt.IS_NULL().OR(RoomSanction.ExpiresAt.GT(Int64(now)))),
+	)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	} else if len(dest) == 0 {
+		return nil, nil
+	}
+
+	longest := lo.MaxBy(dest, func(a model.RoomSanction, b model.RoomSanction) bool {
+		return b.ExpiresAt != nil && (a.ExpiresAt == nil || *a.ExpiresAt > *b.ExpiresAt)
+	})
+
+	return &longest, nil
+}
+
+// Lift ends every sanction of a kind in effect on a user in a group, returning
+// the number of sanctions lifted.
+func Lift(tx qrm.DB, user int64, group int64, kind string) (int64, error) {
+	now := time.Now().Unix()
+
+	stmt := RoomSanction.UPDATE(RoomSanction.LiftedAt).SET(Int64(now)).WHERE(
+		RoomSanction.UserID.EQ(Int64(user)).
+			AND(RoomSanction.RoomID.EQ(Int64(group))).
+			AND(RoomSanction.Kind.EQ(String(kind))).
+			AND(RoomSanction.LiftedAt.IS_NULL()).
+			AND(RoomSanction.ExpiresAt.IS_NULL().OR(RoomSanction.ExpiresAt.GT(Int64(now)))),
+	)
+
+	res, err := stmt.Exec(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
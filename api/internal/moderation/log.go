@@ -0,0 +1,29 @@
+package moderation
+
+import (
+	"time"
+
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+)
+
+// Record adds an action taken by a moderator to the moderation log of a group.
+func Record(tx qrm.DB, entry model.ModerationLog) (model.ModerationLog, error) {
+	entry.Iat = time.Now().Unix()
+
+	var dest model.ModerationLog
+	stmt := ModerationLog.INSERT(
+		ModerationLog.RoomID,
+		ModerationLog.ModeratorID,
+		ModerationLog.UserID,
+		ModerationLog.MessageID,
+		ModerationLog.Action,
+		ModerationLog.Reason,
+		ModerationLog.Iat,
+	).MODEL(entry).RETURNING(ModerationLog.AllColumns)
+
+	err := stmt.Query(tx, &dest)
+	return dest, err
+}
@@ -0,0 +1,125 @@
+package moderation
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// Actions taken when a rule matches, from least to most severe.
+const (
+	ActionMask   = "mask"
+	ActionFlag   = "flag"
+	ActionReject = "reject"
+)
+
+var Actions = []string{ActionMask, ActionFlag, ActionReject}
+
+// Fields that content is checked for, recorded alongside flags.
+const (
+	FieldMessage     = "message"
+	FieldBio         = "bio"
+	FieldDisplayName = "display_name"
+	FieldGroupName   = "group_name"
+	FieldGroupDesc   = "group_description"
+)
+
+var ErrRejected = errors.New("contents rejected by moderation")
+
+// Result is the outcome of checking content that was not rejected.
+type Result struct {
+	// Contents with every masked word replaced by asterisks
+	Contents string
+
+	// Flagged are the words that matched a rule with the flag action
+	Flagged []string
+}
+
+// rules gets the site-wide rules, along with those of a group if given.
+func rules(group *int64) ([]model.ModerationRule, error) {
+	cond := ModerationRule.RoomID.IS_NULL()
+	if group != nil {
+		cond = cond.OR(ModerationRule.RoomID.EQ(Int64(*group)))
+	}
+
+	var dest []model.ModerationRule
+	if err := SELECT(ModerationRule.AllColumns).FROM(ModerationRule).WHERE(cond).Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func pattern(words []string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(lo.Map(words, func(x string, _ int) string {
+		return regexp.QuoteMeta(x)
+	}), "|") + `)\b`)
+}
+
+// Check runs content through the site-wide rules and, if given, those of a
+// group. ErrRejected is returned if any rule with the reject action matches.
+func Check(group *int64, contents string) (Result, error) {
+	rules, err := rules(group)
+	if err != nil {
+		return Result{}, err
+	}
+
+	words := make(map[string][]string)
+	for _, rule := range rules {
+		words[rule.Action] = append(words[rule.Action], rule.Word)
+	}
+
+	if len(words[ActionReject]) > 0 && pattern(words[ActionReject]).MatchString(contents) {
+		return Result{}, ErrRejected
+	}
+
+	var flagged []string
+	if len(words[ActionFlag]) > 0 {
+		flagged = lo.Uniq(lo.Map(pattern(words[ActionFlag]).FindAllString(contents, -1), func(x string, _ int) string {
+			return strings.ToLower(x)
+		}))
+	}
+
+	if len(words[ActionMask]) > 0 {
+		contents = pattern(words[ActionMask]).ReplaceAllStringFunc(contents, func(x string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(x))
+		})
+	}
+
+	return Result{contents, flagged}, nil
+}
+
+// Flag records flagged content for review. The group and message may be nil
+// for content outside of a group, such as a user's bio.
+func Flag(user int64, group *int64, message *int64, field string, contents string, words []string) error {
+	stmt := ModerationFlag.INSERT(
+		ModerationFlag.UserID,
+		ModerationFlag.RoomID,
+		ModerationFlag.MessageID,
+		ModerationFlag.Field,
+		ModerationFlag.Contents,
+		ModerationFlag.Words,
+		ModerationFlag.Iat,
+	).MODEL(model.ModerationFlag{
+		UserID:    user,
+		RoomID:    group,
+		MessageID: message,
+		Field:     field,
+		Contents:  contents,
+		Words:     strings.Join(words, ","),
+		Iat:       time.Now().Unix(),
+	})
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
@@ -3,24 +3,30 @@ package options
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
 type Options struct {
-	TokenSecret      string
-	Hostname         string
-	Hostport         int
-	SslEnabled       bool
-	BasePath         string
-	DatabaseHostname string
-	DatabasePort     int
-	DatabaseName     string
-	DatabaseUsername string
-	DatabasePassword string
-	Port             int
-	ImageFolderPath  string
+	TokenSecret          string
+	Hostname             string
+	Hostport             int
+	SslEnabled           bool
+	BasePath             string
+	DatabaseHostname     string
+	DatabasePort         int
+	DatabaseName         string
+	DatabaseUsername     string
+	DatabasePassword     string
+	Port                 int
+	ImageFolderPath      string
+	AttachmentFolderPath string
+	ExportFolderPath     string
+	DefaultRetention     string
+	TermEnds             []string
+	ArchiveFolderPath    string
 }
 
 func require[T any](v T, err error) T {
@@ -90,18 +96,25 @@ func LoadFromEnvironment() Options {
 		panic("Invalid FQDN")
 	}
 
+	imageFolderPath := require(getSecret("API_IMAGE_FOLDER"))
+
 	return Options{
-		TokenSecret:      require(getSecret("API_TOKEN_SECRET")),
-		Hostname:         match[2],
-		Hostport:         port,
-		SslEnabled:       match[1] == "https",
-		BasePath:         match[4],
-		DatabaseHostname: require(getString("API_DATABASE_HOSTNAME")),
-		DatabasePort:     otherwise(5432)(getInt("API_DATABASE_PORT")),
-		DatabaseName:     otherwise("motmot")(getString("API_DATABASE_NAME")),
-		DatabaseUsername: otherwise("motmot")(getString("API_DATABASE_USERNAME")),
-		DatabasePassword: require(getSecret("API_DATABASE_PASSWORD")),
-		Port:             otherwise(8080)(getInt("API_PORT")),
-		ImageFolderPath:  require(getSecret("API_IMAGE_FOLDER")),
+		TokenSecret:          require(getSecret("API_TOKEN_SECRET")),
+		Hostname:             match[2],
+		Hostport:             port,
+		SslEnabled:           match[1] == "https",
+		BasePath:             match[4],
+		DatabaseHostname:     require(getString("API_DATABASE_HOSTNAME")),
+		DatabasePort:         otherwise(5432)(getInt("API_DATABASE_PORT")),
+		DatabaseName:         otherwise("motmot")(getString("API_DATABASE_NAME")),
+		DatabaseUsername:     otherwise("motmot")(getString("API_DATABASE_USERNAME")),
+		DatabasePassword:     require(getSecret("API_DATABASE_PASSWORD")),
+		Port:                 otherwise(8080)(getInt("API_PORT")),
+		ImageFolderPath:      imageFolderPath,
+		AttachmentFolderPath: otherwise(filepath.Join(imageFolderPath, "attachments"))(getString("API_ATTACHMENT_FOLDER")),
+		ExportFolderPath:     otherwise(filepath.Join(imageFolderPath, "exports"))(getString("API_EXPORT_FOLDER")),
+		DefaultRetention:     otherwise("forever")(getString("API_RETENTION")),
+		TermEnds:             strings.Split(otherwise("05-10,08-10,12-20")(getString("API_TERM_ENDS")), ","),
+		ArchiveFolderPath:    otherwise("")(getString("API_ARCHIVE_FOLDER")),
 	}
 }
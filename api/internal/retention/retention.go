@@ -0,0 +1,324 @@
+package retention
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+)
+
+// How long messages of a group are kept.
+const (
+	KeepForever = "forever"
+	KeepDays    = "days"
+	KeepTerm    = "term"
+)
+
+// PurgeInterval is how often expired messages are purged.
+const PurgeInterval = time.Hour
+
+// batchSize is the number of messages purged in a single transaction.
+const batchSize = 500
+
+var ErrPolicy = errors.New("invalid retention policy")
+
+// Policy is how long the messages of a group are kept before being purged.
+type Policy struct {
+	Keep string `json:"keep"`
+	Days int64  `json:"days,omitempty"`
+}
+
+func (p Policy) validate() error {
+	switch p.Keep {
+	case KeepForever, KeepTerm:
+		return nil
+	case KeepDays:
+		if p.Days > 0 {
+			return nil
+		}
+	}
+
+	return ErrPolicy
+}
+
+// Default gets the site-wide policy, set as either forever, term, or a number
+// of days. An invalid setting keeps messages forever.
+func Default() Policy {
+	switch s := globals.Opts.DefaultRetention; s {
+	case KeepForever, KeepTerm:
+		return Policy{Keep: s}
+	default:
+		if days, err := strconv.ParseInt(s, 10, 64); err == nil && days > 0 {
+			return Policy{KeepDays, days}
+		}
+
+		return Policy{Keep: KeepForever}
+	}
+}
+
+// Of gets the policy of a group, falling back to the site-wide default.
+func Of(group model.Room) Policy {
+	if group.Retention == nil {
+		return Default()
+	}
+
+	return Policy{*group.Retention, lo.FromPtr(group.RetentionDays)}
+}
+
+// Set changes the policy of a group. A nil policy restores the site-wide
+// default.
+func Set(group int64, policy *Policy) error {
+	var keep *string
+	var days *int64
+
+	if policy != nil {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+
+		keep = &policy.Keep
+		if policy.Keep == KeepDays {
+			days = &policy.Days
+		}
+	}
+
+	stmt := Room.UPDATE(Room.Retention, Room.RetentionDays).
+		MODEL(model.Room{Retention: keep, RetentionDays: days}).
+		WHERE(Room.ID.EQ(Int64(group)))
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// termEnd gets the most recent end of term before a time. Terms end on the
+// same days every year.
+func termEnd(now time.Time) (time.Time, bool) {
+	var latest time.Time
+
+	for _, s := range globals.Opts.TermEnds {
+		day, err := time.Parse("01-02", s)
+		if err != nil {
+			continue
+		}
+
+		for _, year := range []int{now.Year(), now.Year() - 1} {
+			end := time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+			if end.Before(now) && end.After(latest) {
+				latest = end
+			}
+		}
+	}
+
+	return latest, !latest.IsZero()
+}
+
+// Cutoff gets the time before which messages are purged under a policy. The
+// second return value is false if no messages are purged.
+func (p Policy) Cutoff(now time.Time) (int64, bool) {
+	switch p.Keep {
+	case KeepDays:
+		return now.AddDate(0, 0, -int(p.Days)).Unix(), true
+	case KeepTerm:
+		end, ok := termEnd(now)
+		return end.Unix(), ok
+	default:
+		return 0, false
+	}
+}
+
+// archived is a purged message as written to an archive.
+type archived struct {
+	ID         int64  `json:"message_id"`
+	Identifier string `json:"user_ident"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+	EditedAt   *int64 `json:"edited_at,omitempty"`
+	DeletedAt  *int64 `json:"deleted_at,omitempty"`
+	ParentID   *int64 `json:"parent_id,omitempty"`
+}
+
+type purgeRow struct {
+	model.RoomMessage
+
+	User model.UserAccount
+}
+
+// archive writes messages about to be purged from a group to a compressed
+// file of JSON lines.
+func archive(group int64, rows []purgeRow) error {
+	folder := filepath.Join(globals.Opts.ArchiveFolderPath, strconv.FormatInt(group, 10))
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(folder, fmt.Sprintf("%d-%d.jsonl.gz", rows[0].ID, rows[len(rows)-1].ID)))
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	w := gzip.NewWriter(file)
+	enc := json.NewEncoder(w)
+
+	for _, x := range rows {
+		if err := enc.Encode(archived{x.ID, x.User.Identifier, x.Contents, x.Iat, x.EditedAt, x.DeletedAt, x.ParentID}); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// purgeBatch deletes up to a batch of messages of a group sent before a
+// cutoff, returning the number deleted. Thread parents are kept while any of
+// their replies are, and so are purged by a later batch once their replies
+// have expired.
+func purgeBatch(group int64, cutoff int64) (int, error) {
+	stmt := SELECT(
+		RoomMessage.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+	).FROM(
+		RoomMessage.LEFT_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		RoomMessage.RoomID.EQ(Int64(group)).
+			AND(RoomMessage.Iat.LT(Int64(cutoff))).
+			AND(RoomMessage.ID.NOT_IN(
+				SELECT(RoomMessage.ParentID).FROM(RoomMessage).WHERE(
+					RoomMessage.RoomID.EQ(Int64(group)).AND(RoomMessage.ParentID.IS_NOT_NULL()),
+				),
+			)),
+	).ORDER_BY(RoomMessage.ID.ASC()).LIMIT(batchSize)
+
+	var rows []purgeRow
+	if err := stmt.Query(globals.Database, &rows); err != nil && err != qrm.ErrNoRows {
+		return 0, err
+	} else if len(rows) == 0 {
+		return 0, nil
+	}
+
+	if globals.Opts.ArchiveFolderPath != "" {
+		if err := archive(group, rows); err != nil {
+			return 0, err
+		}
+	}
+
+	ids := lo.Map(rows, func(x purgeRow, _ int) Expression { return Int64(x.ID) })
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	// Files are only removed once nothing refers to them after the commit
+	var attachments []model.MessageAttachment
+	if err := SELECT(MessageAttachment.ID, MessageAttachment.Hash).FROM(MessageAttachment).WHERE(MessageAttachment.MessageID.IN(ids...)).Query(tx, &attachments); err != nil && err != qrm.ErrNoRows {
+		return 0, err
+	}
+
+	// Every table referring to messages must be cleared here. Records kept for
+	// accountability lose their link to the message rather than being deleted.
+	stmts := []Statement{
+		MessageReaction.DELETE().WHERE(MessageReaction.MessageID.IN(ids...)),
+		MessageMention.DELETE().WHERE(MessageMention.MessageID.IN(ids...)),
+		MessagePin.DELETE().WHERE(MessagePin.MessageID.IN(ids...)),
+		MessageBookmark.DELETE().WHERE(MessageBookmark.MessageID.IN(ids...)),
+		Notification.DELETE().WHERE(Notification.MessageID.IN(ids...)),
+		MessageAttachment.DELETE().WHERE(MessageAttachment.MessageID.IN(ids...)),
+		MessageReport.DELETE().WHERE(MessageReport.MessageID.IN(ids...)),
+		RoomMessageRevision.DELETE().WHERE(RoomMessageRevision.MessageID.IN(ids...)),
+		PollVote.DELETE().WHERE(PollVote.MessageID.IN(ids...)),
+		PollOption.DELETE().WHERE(PollOption.MessageID.IN(ids...)),
+		Poll.DELETE().WHERE(Poll.MessageID.IN(ids...)),
+		ModerationLog.UPDATE(ModerationLog.MessageID).SET(NULL).WHERE(ModerationLog.MessageID.IN(ids...)),
+		ModerationFlag.UPDATE(ModerationFlag.MessageID).SET(NULL).WHERE(ModerationFlag.MessageID.IN(ids...)),
+		ScheduledMessage.UPDATE(ScheduledMessage.LastMessageID).SET(NULL).WHERE(ScheduledMessage.LastMessageID.IN(ids...)),
+		RoomMessage.DELETE().WHERE(RoomMessage.ID.IN(ids...)),
+	}
+
+	for _, stmt := range stmts {
+		if _, err := stmt.Exec(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if err := chat.RemoveUnreferenced(lo.Map(attachments, func(x model.MessageAttachment, _ int) string { return x.Hash })); err != nil {
+		fmt.Printf("[retention] Failed to remove attachments of group %d: %s\n", group, err.Error())
+	}
+
+	return len(rows), nil
+}
+
+// Purge deletes the messages of a group that have expired under its policy.
+func Purge(group model.Room, now time.Time) (int, error) {
+	cutoff, ok := Of(group).Cutoff(now)
+	if !ok {
+		return 0, nil
+	}
+
+	total := 0
+	for {
+		n, err := purgeBatch(group.ID, cutoff)
+		total += n
+
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+func purgeAll() {
+	var groups []model.Room
+	if err := SELECT(Room.ID, Room.Retention, Room.RetentionDays).FROM(Room).Query(globals.Database, &groups); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[retention] Failed to query database: %s\n", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, group := range groups {
+		if n, err := Purge(group, now); err != nil {
+			fmt.Printf("[retention] Failed to purge group %d: %s\n", group.ID, err.Error())
+		} else if n > 0 {
+			fmt.Printf("[retention] Purged %d messages from group %d\n", n, group.ID)
+		}
+	}
+
+	if n, err := chat.ExpireUploads(now.Unix() - chat.UploadExpiry); err != nil {
+		fmt.Printf("[retention] Failed to expire uploads: %s\n", err.Error())
+	} else if n > 0 {
+		fmt.Printf("[retention] Expired %d unattached uploads\n", n)
+	}
+}
+
+// Worker periodically purges expired messages from every group, along with
+// uploads never attached to a message, starting as soon as it is run.
+func Worker() {
+	purgeAll()
+
+	for range time.Tick(PurgeInterval) {
+		purgeAll()
+	}
+}
@@ -0,0 +1,357 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/chat"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+// MaxPending caps the number of messages a user may have waiting to be sent.
+const MaxPending = 25
+
+// MinRepeatInterval is the shortest time in seconds between repeats of a
+// recurring message.
+const MinRepeatInterval = 60 * 60
+
+// PollInterval is how often the worker looks for messages that are due.
+const PollInterval = 15 * time.Second
+
+const (
+	StatusPending  = "pending"
+	StatusSending  = "sending"
+	StatusSent     = "sent"
+	StatusFailed   = "failed"
+	StatusCanceled = "canceled"
+)
+
+var (
+	ErrNotFound = errors.New("scheduled message not found")
+	ErrTime     = errors.New("invalid schedule time")
+	ErrRepeat   = errors.New("invalid repeat interval")
+	ErrLimit    = errors.New("too many scheduled messages")
+)
+
+// Plan is what to post and when.
+type Plan struct {
+	Contents string
+	SendAt   int64
+
+	// RepeatEvery is the number of seconds between posts of a recurring
+	// message, which stops after RepeatUntil if given
+	RepeatEvery *int64
+	RepeatUntil *int64
+}
+
+func (p *Plan) validate(group int64) error {
+	p.Contents = strings.TrimSpace(p.Contents)
+	if len(p.Contents) == 0 || len(p.Contents) > chat.MaxContentsLength {
+		return chat.ErrInvalid
+	}
+
+	if p.SendAt <= time.Now().Unix() {
+		return ErrTime
+	}
+
+	if p.RepeatEvery != nil && *p.RepeatEvery < MinRepeatInterval {
+		return ErrRepeat
+	} else if p.RepeatUntil != nil && (p.RepeatEvery == nil || *p.RepeatUntil < p.SendAt) {
+		return ErrRepeat
+	}
+
+	// Rejected contents are refused now rather than failing when sent
+	_, err := moderation.Check(&group, p.Contents)
+	return err
+}
+
+// Create schedules a message from a member of a group.
+func Create(user model.UserAccount, group int64, plan Plan) (model.ScheduledMessage, error) {
+	if role, err := member.Role(user.ID, group); err != nil {
+		return model.ScheduledMessage{}, err
+	} else if role == "" {
+		return model.ScheduledMessage{}, chat.ErrNotMember
+	}
+
+	if err := plan.validate(group); err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	var count struct {
+		Count int64 `alias:"count"`
+	}
+
+	stmt := SELECT(COUNT(ScheduledMessage.ID).AS("count")).FROM(ScheduledMessage).WHERE(
+		ScheduledMessage.UserID.EQ(Int64(user.ID)).AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	)
+
+	if err := stmt.Query(globals.Database, &count); err != nil {
+		return model.ScheduledMessage{}, err
+	} else if count.Count >= MaxPending {
+		return model.ScheduledMessage{}, ErrLimit
+	}
+
+	var dest model.ScheduledMessage
+	ins := ScheduledMessage.INSERT(
+		ScheduledMessage.UserID,
+		ScheduledMessage.RoomID,
+		ScheduledMessage.Contents,
+		ScheduledMessage.SendAt,
+		ScheduledMessage.RepeatEvery,
+		ScheduledMessage.RepeatUntil,
+		ScheduledMessage.Status,
+		ScheduledMessage.Iat,
+	).MODEL(model.ScheduledMessage{
+		UserID:      user.ID,
+		RoomID:      group,
+		Contents:    plan.Contents,
+		SendAt:      plan.SendAt,
+		RepeatEvery: plan.RepeatEvery,
+		RepeatUntil: plan.RepeatUntil,
+		Status:      StatusPending,
+		Iat:         time.Now().Unix(),
+	}).RETURNING(ScheduledMessage.AllColumns)
+
+	err := ins.Query(globals.Database, &dest)
+	return dest, err
+}
+
+// Item is a message waiting to be sent, along with its author.
+type Item struct {
+	model.ScheduledMessage
+
+	User model.UserAccount
+}
+
+func pending(cond BoolExpression) ([]Item, error) {
+	stmt := SELECT(
+		ScheduledMessage.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+	).FROM(
+		ScheduledMessage.INNER_JOIN(UserAccount, ScheduledMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(
+		cond.AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	).ORDER_BY(ScheduledMessage.SendAt.ASC())
+
+	var dest []Item
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Pending gets the messages a user has waiting to be sent, soonest first,
+// optionally only those in one group.
+func Pending(user int64, group *int64) ([]Item, error) {
+	cond := ScheduledMessage.UserID.EQ(Int64(user))
+	if group != nil {
+		cond = cond.AND(ScheduledMessage.RoomID.EQ(Int64(*group)))
+	}
+
+	return pending(cond)
+}
+
+// PendingInGroup gets the messages every member of a group has waiting to be
+// sent, soonest first, so that a moderator may find those to cancel.
+func PendingInGroup(user int64, group int64) ([]Item, error) {
+	if ok, err := member.IsModerator(user, group); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, chat.ErrForbidden
+	}
+
+	return pending(ScheduledMessage.RoomID.EQ(Int64(group)))
+}
+
+// Update replaces the plan of a message a user has waiting to be sent.
+func Update(user model.UserAccount, id int64, plan Plan) (model.ScheduledMessage, error) {
+	var current model.ScheduledMessage
+	stmt := SELECT(ScheduledMessage.AllColumns).FROM(ScheduledMessage).WHERE(
+		ScheduledMessage.ID.EQ(Int64(id)).
+			AND(ScheduledMessage.UserID.EQ(Int64(user.ID))).
+			AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	)
+
+	if err := stmt.Query(globals.Database, &current); err == qrm.ErrNoRows {
+		return model.ScheduledMessage{}, ErrNotFound
+	} else if err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	if err := plan.validate(current.RoomID); err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	current.Contents = plan.Contents
+	current.SendAt = plan.SendAt
+	current.RepeatEvery = plan.RepeatEvery
+	current.RepeatUntil = plan.RepeatUntil
+
+	var dest model.ScheduledMessage
+	upd := ScheduledMessage.UPDATE(
+		ScheduledMessage.Contents,
+		ScheduledMessage.SendAt,
+		ScheduledMessage.RepeatEvery,
+		ScheduledMessage.RepeatUntil,
+	).MODEL(current).WHERE(
+		ScheduledMessage.ID.EQ(Int64(id)).AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	).RETURNING(ScheduledMessage.AllColumns)
+
+	// The worker may have claimed the message since it was read
+	if err := upd.Query(globals.Database, &dest); err == qrm.ErrNoRows {
+		return model.ScheduledMessage{}, ErrNotFound
+	} else if err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	return dest, nil
+}
+
+// Cancel stops a message from being sent. Moderators may cancel messages
+// scheduled by anyone in their groups.
+func Cancel(user model.UserAccount, id int64) error {
+	var current model.ScheduledMessage
+	stmt := SELECT(ScheduledMessage.ID, ScheduledMessage.UserID, ScheduledMessage.RoomID).FROM(ScheduledMessage).WHERE(
+		ScheduledMessage.ID.EQ(Int64(id)).AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	)
+
+	if err := stmt.Query(globals.Database, &current); err == qrm.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if current.UserID != user.ID {
+		if ok, err := member.IsModerator(user.ID, current.RoomID); err != nil {
+			return err
+		} else if !ok {
+			return chat.ErrForbidden
+		}
+	}
+
+	upd := ScheduledMessage.UPDATE(ScheduledMessage.Status).SET(String(StatusCanceled)).WHERE(
+		ScheduledMessage.ID.EQ(Int64(id)).AND(ScheduledMessage.Status.EQ(String(StatusPending))),
+	)
+
+	if res, err := upd.Exec(globals.Database); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// deliver posts a claimed message, then either schedules its next repeat or
+// marks it finished. Occurrences the author may not post, such as while muted,
+// are skipped; if they have left the group the message is failed. Messages
+// sent once are instead held back until the posting policy of the group lets
+// the author post, such as after slow mode.
+func deliver(s model.ScheduledMessage) error {
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.ID.EQ(Int64(s.UserID))).Query(globals.Database, &user); err != nil {
+		return err
+	}
+
+	s.Status = StatusSent
+
+	if role, err := member.Role(user.ID, s.RoomID); err != nil {
+		return err
+	} else if role == "" {
+		s.Status = StatusFailed
+	} else {
+		msg, err := chat.Post(user, s.RoomID, chat.Draft{Contents: s.Contents}, nil)
+
+		var restriction *chat.Restriction
+		switch {
+		case err == nil:
+			s.LastMessageID = &msg.ID
+		case errors.As(err, &restriction) && restriction.RetryAt != nil && s.RepeatEvery == nil:
+			s.Status = StatusPending
+			s.SendAt = *restriction.RetryAt
+		case err == chat.ErrMuted, err == member.ErrBlocked, err == chat.ErrInvalid, err == moderation.ErrRejected, errors.As(err, &restriction):
+			s.Status = StatusFailed
+		default:
+			return err
+		}
+
+		if s.RepeatEvery != nil {
+			// Occurrences missed while the server was down are not sent late
+			now := time.Now().Unix()
+			next := s.SendAt + *s.RepeatEvery
+			if next <= now {
+				next += (now-next) / *s.RepeatEvery * *s.RepeatEvery + *s.RepeatEvery
+			}
+
+			if s.RepeatUntil == nil || next <= *s.RepeatUntil {
+				s.Status = StatusPending
+				s.SendAt = next
+			}
+		}
+	}
+
+	stmt := ScheduledMessage.UPDATE(ScheduledMessage.Status, ScheduledMessage.SendAt, ScheduledMessage.LastMessageID).
+		MODEL(s).
+		WHERE(ScheduledMessage.ID.EQ(Int64(s.ID)))
+
+	_, err := stmt.Exec(globals.Database)
+	return err
+}
+
+// claim marks every message that is due as being sent, so that edits and
+// cancellations no longer apply to them.
+func claim() ([]model.ScheduledMessage, error) {
+	var dest []model.ScheduledMessage
+	stmt := ScheduledMessage.UPDATE(ScheduledMessage.Status).SET(String(StatusSending)).WHERE(
+		ScheduledMessage.Status.EQ(String(StatusPending)).AND(ScheduledMessage.SendAt.LT_EQ(Int64(time.Now().Unix()))),
+	).RETURNING(ScheduledMessage.AllColumns)
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Worker posts scheduled messages as they come due. Messages left claimed by a
+// previous run of the server are returned to pending first.
+func Worker() {
+	reset := ScheduledMessage.UPDATE(ScheduledMessage.Status).SET(String(StatusPending)).WHERE(
+		ScheduledMessage.Status.EQ(String(StatusSending)),
+	)
+
+	if _, err := reset.Exec(globals.Database); err != nil {
+		fmt.Printf("[schedule] Failed to execute query on database: %s\n", err.Error())
+	}
+
+	for range time.Tick(PollInterval) {
+		due, err := claim()
+		if err != nil {
+			fmt.Printf("[schedule] Failed to query database: %s\n", err.Error())
+			continue
+		}
+
+		for _, s := range due {
+			if err := deliver(s); err != nil {
+				fmt.Printf("[schedule] Failed to deliver message %d: %s\n", s.ID, err.Error())
+
+				// Leave it to be picked up again on the next tick
+				s.Status = StatusPending
+				stmt := ScheduledMessage.UPDATE(ScheduledMessage.Status).MODEL(s).WHERE(ScheduledMessage.ID.EQ(Int64(s.ID)))
+				if _, err := stmt.Exec(globals.Database); err != nil {
+					fmt.Printf("[schedule] Failed to execute query on database: %s\n", err.Error())
+				}
+			}
+		}
+	}
+}
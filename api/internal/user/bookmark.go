@@ -0,0 +1,178 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	DefaultBookmarksLimit = 20
+	MaxBookmarksLimit     = 50
+)
+
+// bookmarkCursor marks a position in a list of bookmarks for keyset
+// pagination. It is handed to clients as an opaque string.
+type bookmarkCursor struct {
+	Iat int64 `json:"t"`
+	ID  int64 `json:"i"`
+}
+
+func (c bookmarkCursor) String() string {
+	p, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(p)
+}
+
+func parseBookmarkCursor(s string) (bookmarkCursor, error) {
+	var c bookmarkCursor
+
+	p, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(p, &c)
+	return c, err
+}
+
+type BookmarksResponseItem struct {
+	ID           int64   `json:"message_id"`
+	GroupID      int64   `json:"group_id"`
+	GroupName    string  `json:"group_name"`
+	Identifier   string  `json:"user_ident,omitempty"`
+	DisplayName  string  `json:"display_name,omitempty"`
+	Kind         string  `json:"kind"`
+	Contents     string  `json:"contents"`
+	IssuedAt     int64   `json:"iat"`
+	ParentID     *int64  `json:"parent_id,omitempty"`
+	Note         *string `json:"note,omitempty"`
+	BookmarkedAt int64   `json:"bookmarked_at"`
+}
+
+type BookmarksResponse struct {
+	Bookmarks []BookmarksResponseItem `json:"bookmarks"`
+	Next      *string                 `json:"next_cursor"`
+}
+
+type bookmarkRow struct {
+	model.MessageBookmark
+
+	Message model.RoomMessage
+	Room    model.Room
+	Author  model.UserAccount
+}
+
+// Bookmarks godoc
+// @Summary Get bookmarks
+// @Description Returns the messages bookmarked by the user across all groups, most recently bookmarked first. Messages in private groups the user has since left are left out.
+// @Tags user
+// @Produce json
+// @Success 200 {object} BookmarksResponse
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param limit  query int64  false "Max number of bookmarks to retreive (<= 50, default 20)"
+// @Param cursor query string false "Cursor from a previous page to continue from"
+// @Router /user/bookmarks [get]
+func Bookmarks(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request struct {
+		Limit  int64  `form:"limit"`
+		Cursor string `form:"cursor"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = DefaultBookmarksLimit
+	} else if request.Limit > MaxBookmarksLimit {
+		request.Limit = MaxBookmarksLimit
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/bookmarks] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	cond := MessageBookmark.UserID.EQ(Int64(user.ID)).
+		AND(RoomMessage.DeletedAt.IS_NULL()).
+		AND(Room.Visibility.NOT_EQ(String(member.VisibilityPrivate)).OR(EXISTS(
+			SELECT(UserRoom.UserID).FROM(UserRoom).WHERE(
+				UserRoom.RoomID.EQ(Room.ID).AND(UserRoom.UserID.EQ(Int64(user.ID))),
+			),
+		)))
+
+	if request.Cursor != "" {
+		cur, err := parseBookmarkCursor(request.Cursor)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		iat, id := Int64(cur.Iat), Int64(cur.ID)
+		cond = cond.AND(MessageBookmark.Iat.LT(iat).OR(MessageBookmark.Iat.EQ(iat).AND(MessageBookmark.MessageID.LT(id))))
+	}
+
+	stmt := SELECT(
+		MessageBookmark.AllColumns,
+		RoomMessage.ID, RoomMessage.Kind, RoomMessage.Contents, RoomMessage.Iat, RoomMessage.ParentID,
+		Room.ID, Room.Name,
+		UserAccount.ID, UserAccount.Identifier, UserAccount.DisplayName,
+	).FROM(
+		MessageBookmark.
+			INNER_JOIN(RoomMessage, MessageBookmark.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(Room, RoomMessage.RoomID.EQ(Room.ID)).
+			LEFT_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(MessageBookmark.Iat.DESC(), MessageBookmark.MessageID.DESC()).LIMIT(request.Limit + 1)
+
+	var dest []bookmarkRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/user/bookmarks] Failed to query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	var response BookmarksResponse
+	if int64(len(dest)) > request.Limit {
+		dest = dest[:request.Limit]
+
+		last := dest[len(dest)-1]
+		response.Next = lo.ToPtr(bookmarkCursor{last.Iat, last.MessageID}.String())
+	}
+
+	response.Bookmarks = lo.Map(dest, func(x bookmarkRow, _ int) BookmarksResponseItem {
+		return BookmarksResponseItem{
+			ID:           x.MessageID,
+			GroupID:      x.Room.ID,
+			GroupName:    x.Room.Name,
+			Identifier:   x.Author.Identifier,
+			DisplayName:  x.Author.DisplayName,
+			Kind:         x.Message.Kind,
+			Contents:     x.Message.Contents,
+			IssuedAt:     x.Message.Iat,
+			ParentID:     x.Message.ParentID,
+			Note:         x.Note,
+			BookmarkedAt: x.Iat,
+		}
+	})
+
+	c.JSON(http.StatusOK, response)
+}
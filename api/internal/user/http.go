@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/go-jet/jet/v2/postgres"
@@ -19,8 +20,12 @@ import (
 	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
 	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
 	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
 	"github.com/tetrago/motmot/api/internal/crypt"
 	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
 )
 
 type GetResponseGroup struct {
@@ -38,7 +43,7 @@ type GetResponse struct {
 
 // User godoc
 // @Summary Fetch user
-// @Description Fetches publically available user information and groups.
+// @Description Fetches publically available user information and groups, leaving out private groups and direct conversations. Users may limit their groups to those shared with the viewer, or hide them entirely.
 // @Tags user
 // @Produce json
 // @Success 200 {object} GetResponse
@@ -56,19 +61,35 @@ func Get(c *gin.Context) {
 		return
 	}
 
+	var caller model.UserAccount
+	if token := auth.OptionalToken(c); token != nil {
+		if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &caller); err != nil && err != qrm.ErrNoRows {
+			fmt.Printf("[/user/get] Error querying database: %s\n", err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
 	var dest struct {
 		model.UserAccount
 
 		Rooms []model.Room
 	}
 
+	shared := Room.ID.IN(SELECT(UserRoom.RoomID).FROM(UserRoom).WHERE(UserRoom.UserID.EQ(Int64(caller.ID))))
+	visible := Room.Visibility.NOT_EQ(String(member.VisibilityPrivate)).AND(
+		UserAccount.Privacy.EQ(String(member.PrivacyPublic)).
+			OR(UserAccount.ID.EQ(Int64(caller.ID))).
+			OR(UserAccount.Privacy.EQ(String(member.PrivacyMembers)).AND(shared)),
+	)
+
 	stmt := SELECT(
-		UserAccount.Identifier, UserAccount.DisplayName, UserAccount.Email, UserAccount.Bio,
+		UserAccount.ID, UserAccount.Identifier, UserAccount.DisplayName, UserAccount.Email, UserAccount.Bio,
 		Room.ID, Room.Name,
 	).FROM(
 		UserAccount.
 			LEFT_JOIN(UserRoom, UserAccount.ID.EQ(UserRoom.UserID)).
-			LEFT_JOIN(Room, UserRoom.RoomID.EQ(Room.ID)),
+			LEFT_JOIN(Room, UserRoom.RoomID.EQ(Room.ID).AND(visible)),
 	).WHERE(
 		UserAccount.Identifier.EQ(String(uri.Identifier)),
 	)
@@ -133,6 +154,16 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	name, err := moderation.Check(nil, request.DisplayName)
+	if err == moderation.ErrRejected {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/user/register] Failed to moderate display name: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
 	ident, err := MakeIdentifier()
 	if err != nil {
 		fmt.Printf("[/user/register] Error generating identifier: %s\n", err.Error())
@@ -144,7 +175,7 @@ func Register(c *gin.Context) {
 	ins := UserAccount.INSERT(UserAccount.Identifier, UserAccount.DisplayName, UserAccount.Hash, UserAccount.Email).
 		MODEL(model.UserAccount{
 			Identifier:  ident,
-			DisplayName: request.DisplayName,
+			DisplayName: name.Contents,
 			Hash:        crypt.Hash(request.Password),
 			Email:       request.Email,
 		}).
@@ -153,9 +184,16 @@ func Register(c *gin.Context) {
 	if err := ins.Query(globals.Database, &dest); err != nil {
 		fmt.Printf("[/user/register] Error querying database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
-	} else {
-		c.JSON(http.StatusOK, RegisterResponse{dest.Identifier})
+		return
+	}
+
+	if len(name.Flagged) > 0 {
+		if err := moderation.Flag(dest.ID, nil, nil, moderation.FieldDisplayName, request.DisplayName, name.Flagged); err != nil {
+			fmt.Printf("[/user/register] Failed to flag display name: %s\n", err.Error())
+		}
 	}
+
+	c.JSON(http.StatusOK, RegisterResponse{dest.Identifier})
 }
 
 // Profile Picture godoc
@@ -365,8 +403,18 @@ func DisplayName(c *gin.Context) {
 		return
 	}
 
+	name, err := moderate(token.UserIdentifier(), moderation.FieldDisplayName, request.DisplayName)
+	if err == moderation.ErrRejected {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/user/display_name] Failed to moderate display name: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
 	stmt := UserAccount.UPDATE(UserAccount.DisplayName).MODEL(model.UserAccount{
-		DisplayName: request.DisplayName,
+		DisplayName: name,
 	}).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier())))
 
 	if _, err := stmt.Exec(globals.Database); err != nil {
@@ -402,8 +450,18 @@ func Bio(c *gin.Context) {
 		return
 	}
 
+	bio, err := moderate(token.UserIdentifier(), moderation.FieldBio, request.Bio)
+	if err == moderation.ErrRejected {
+		c.Status(http.StatusBadRequest)
+		return
+	} else if err != nil {
+		fmt.Printf("[/user/bio] Failed to moderate bio: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
 	stmt := UserAccount.UPDATE(UserAccount.Bio).MODEL(model.UserAccount{
-		Bio: &request.Bio,
+		Bio: &bio,
 	}).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier())))
 
 	if _, err := stmt.Exec(globals.Database); err != nil {
@@ -414,12 +472,60 @@ func Bio(c *gin.Context) {
 	}
 }
 
+type PrivacyRequest struct {
+	Privacy string `json:"privacy"`
+}
+
+// Privacy godoc
+// @Summary Updates privacy
+// @Description Updates who may see which groups a user belongs to: everyone (public), only those sharing a group (members), or only moderators of each group (hidden)
+// @Tags user
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body PrivacyRequest true "New privacy setting"
+// @Router /user/privacy [post]
+func Privacy(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request PrivacyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	switch request.Privacy {
+	case member.PrivacyPublic, member.PrivacyMembers, member.PrivacyHidden:
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	stmt := UserAccount.UPDATE(UserAccount.Privacy).MODEL(model.UserAccount{
+		Privacy: request.Privacy,
+	}).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier())))
+
+	if _, err := stmt.Exec(globals.Database); err != nil {
+		fmt.Printf("[/user/privacy] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	} else {
+		c.Status(http.StatusOK)
+	}
+}
+
+type BlockedResponseItem struct {
+	Identifier  string `json:"ident"`
+	DisplayName string `json:"display_name"`
+}
+
 // Blocked godoc
 // @Summary Get blocked users
 // @Description Returns all blocked users
 // @Tags user
 // @Produe json
-// @Success 200 {array} string
+// @Success 200 {array} BlockedResponseItem
 // @Failure 401
 // @Failure 500
 // @Router /user/blocked [get]
@@ -434,16 +540,16 @@ func Blocked(c *gin.Context) {
 	}
 
 	var dest []model.UserAccount
-	stmt := SELECT(UserAccount.Identifier).FROM(
-		UserAccount.RIGHT_JOIN(UserBlock, UserBlock.BlockUserID.EQ(UserAccount.ID)),
-	).WHERE(UserBlock.UserID.EQ(Int64(user.ID)))
+	stmt := SELECT(UserAccount.Identifier, UserAccount.DisplayName).FROM(
+		UserAccount.INNER_JOIN(UserBlock, UserBlock.BlockUserID.EQ(UserAccount.ID)),
+	).WHERE(UserBlock.UserID.EQ(Int64(user.ID))).ORDER_BY(LOWER(UserAccount.DisplayName).ASC())
 
-	if err := stmt.Query(globals.Database, &dest); err != nil {
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
 		fmt.Printf("[/user/blocked] Failed query database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
 	} else {
-		c.JSON(http.StatusOK, lo.Map(dest, func(x model.UserAccount, _ int) string {
-			return x.Identifier
+		c.JSON(http.StatusOK, lo.Map(dest, func(x model.UserAccount, _ int) BlockedResponseItem {
+			return BlockedResponseItem{x.Identifier, x.DisplayName}
 		}))
 	}
 }
@@ -452,9 +558,39 @@ type BlockRequest struct {
 	Identifier string `json:"ident"`
 }
 
+// blockTarget gets the user making a request and the other user named in it,
+// responding with an error if there is no such user or it is the same one. The
+// last return value is false if a response has already been written.
+func blockTarget(c *gin.Context, route string, ident string) (model.UserAccount, model.UserAccount, bool) {
+	token := auth.ExpectToken(c)
+
+	var user, target model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return user, target, false
+	}
+
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(ident))).Query(globals.Database, &target); err == qrm.ErrNoRows {
+		c.Status(http.StatusBadRequest)
+		return user, target, false
+	} else if err != nil {
+		fmt.Printf("[%s] Failed query database: %s\n", route, err.Error())
+		c.Status(http.StatusInternalServerError)
+		return user, target, false
+	}
+
+	if user.ID == target.ID {
+		c.Status(http.StatusBadRequest)
+		return user, target, false
+	}
+
+	return user, target, true
+}
+
 // Block godoc
 // @Summary Block user
-// @Description Blocks a user's messages from being displayed
+// @Description Hides a user's messages from history, search, and live chat. Users may not block themselves.
 // @Tags user
 // @Consume json
 // @Success 200
@@ -470,31 +606,57 @@ func Block(c *gin.Context) {
 		return
 	}
 
-	token := auth.ExpectToken(c)
+	user, block, ok := blockTarget(c, "/user/block", request.Identifier)
+	if !ok {
+		return
+	}
 
-	var user model.UserAccount
-	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+	stmt := UserBlock.INSERT(UserBlock.UserID, UserBlock.BlockUserID).MODEL(model.UserBlock{
+		UserID:      user.ID,
+		BlockUserID: block.ID,
+	}).ON_CONFLICT(UserBlock.UserID, UserBlock.BlockUserID).DO_NOTHING()
+
+	if _, err := stmt.Exec(globals.Database); err != nil {
 		fmt.Printf("[/user/block] Failed query database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
+	} else {
+		hub.Block(user.ID, block.ID)
+		c.Status(http.StatusOK)
+	}
+}
+
+// Unblock godoc
+// @Summary Unblock user
+// @Description Shows a blocked user's messages again
+// @Tags user
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body BlockRequest true "User to unblock"
+// @Router /user/unblock [post]
+func Unblock(c *gin.Context) {
+	var request BlockRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
 		return
 	}
 
-	var block model.UserAccount
-	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(request.Identifier))).Query(globals.Database, &block); err != nil {
-		fmt.Printf("[/user/block] Failed query database: %s\n", err.Error())
-		c.Status(http.StatusInternalServerError)
+	user, block, ok := blockTarget(c, "/user/unblock", request.Identifier)
+	if !ok {
 		return
 	}
 
-	stmt := UserBlock.INSERT(UserBlock.UserID, UserBlock.BlockUserID).MODEL(model.UserBlock{
-		UserID:      user.ID,
-		BlockUserID: block.ID,
-	})
+	stmt := UserBlock.DELETE().WHERE(
+		UserBlock.UserID.EQ(Int64(user.ID)).AND(UserBlock.BlockUserID.EQ(Int64(block.ID))),
+	)
 
 	if _, err := stmt.Exec(globals.Database); err != nil {
-		fmt.Printf("[/user/block] Failed query database: %s\n", err.Error())
+		fmt.Printf("[/user/unblock] Failed query database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
 	} else {
+		hub.Unblock(user.ID, block.ID)
 		c.Status(http.StatusOK)
 	}
 }
@@ -505,12 +667,13 @@ type JoinRequest struct {
 
 // Join godoc
 // @Summary Join group
-// @Description Adds a user to a group
+// @Description Adds a user to a group, unless they are banned from it, and announces it to the group. Private groups may only be joined by invite, and direct conversations not at all.
 // @Tags user
 // @Consume json
 // @Success 200
 // @Failure 400
 // @Failure 401
+// @Failure 403
 // @Failure 500
 // @Param request body JoinRequest true "Group to join"
 // @Router /user/join [post]
@@ -536,7 +699,7 @@ func Join(c *gin.Context) {
 	}
 
 	var room model.Room
-	stmt = Room.SELECT(Room.ID).FROM(Room).WHERE(Room.ID.EQ(Int64(request.GroupID)))
+	stmt = Room.SELECT(Room.ID, Room.Visibility).FROM(Room).WHERE(Room.ID.EQ(Int64(request.GroupID)))
 
 	if err := stmt.Query(globals.Database, &room); err == qrm.ErrNoRows {
 		c.Status(http.StatusBadRequest)
@@ -547,17 +710,58 @@ func Join(c *gin.Context) {
 		return
 	}
 
+	if room.Visibility == member.VisibilityPrivate {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	if sanction, err := member.Active(user.ID, room.ID, member.SanctionBan); err != nil {
+		fmt.Printf("[/user/join] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	} else if sanction != nil {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		fmt.Printf("[/user/join] Failed to begin transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
 	ins := UserRoom.INSERT(UserRoom.UserID, UserRoom.RoomID).MODEL(model.UserRoom{
 		UserID: user.ID,
 		RoomID: room.ID,
-	})
+	}).ON_CONFLICT(UserRoom.UserID, UserRoom.RoomID).DO_NOTHING()
 
-	if _, err := ins.Exec(globals.Database); err != nil {
+	if res, err := ins.Exec(tx); err != nil {
 		fmt.Printf("[/user/join] Failed to execute query on database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
-	} else {
+		return
+	} else if n, _ := res.RowsAffected(); n == 0 {
 		c.Status(http.StatusOK)
+		return
 	}
+
+	msg, err := chat.Announce(tx, room.ID, chat.SystemEvent{Event: chat.SystemJoined, Identifier: token.UserIdentifier()})
+	if err != nil {
+		fmt.Printf("[/user/join] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("[/user/join] Failed to commit transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	hub.Publish(room.ID, msg, nil)
+	c.Status(http.StatusOK)
 }
 
 type LeaveRequest struct {
@@ -566,12 +770,13 @@ type LeaveRequest struct {
 
 // Leave godoc
 // @Summary Leave group
-// @Description Removes a user from a group
+// @Description Removes a user from a group and announces it to the group. Direct conversations may not be left.
 // @Tags user
 // @Consume json
 // @Success 200
 // @Failure 400
 // @Failure 401
+// @Failure 403
 // @Failure 500
 // @Param request body JoinRequest true "Group to leave"
 // @Router /user/leave [post]
@@ -597,27 +802,66 @@ func Leave(c *gin.Context) {
 		return
 	}
 
+	if kind, err := member.Kind(request.GroupID); err != nil {
+		fmt.Printf("[/user/leave] Failed to query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	} else if kind == member.KindDirect {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	tx, err := globals.Database.Begin()
+	if err != nil {
+		fmt.Printf("[/user/leave] Failed to begin transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
 	var entry model.UserRoom
 	del := UserRoom.DELETE().WHERE(UserRoom.UserID.EQ(Int64(dest.ID)).AND(UserRoom.RoomID.EQ(Int64(request.GroupID)))).RETURNING(UserRoom.AllColumns)
 
-	if err := del.Query(globals.Database, &entry); err == qrm.ErrNoRows {
+	if err := del.Query(tx, &entry); err == qrm.ErrNoRows {
 		c.Status(http.StatusBadRequest)
+		return
 	} else if err != nil {
 		fmt.Printf("[/user/leave] Failed to execute query on database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
-	} else {
-		c.Status(http.StatusOK)
+		return
+	}
+
+	msg, err := chat.Announce(tx, request.GroupID, chat.SystemEvent{Event: chat.SystemLeft, Identifier: token.UserIdentifier()})
+	if err != nil {
+		fmt.Printf("[/user/leave] Failed to execute query on database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("[/user/leave] Failed to commit transaction: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
 	}
+
+	hub.Publish(request.GroupID, msg, nil)
+	c.Status(http.StatusOK)
 }
 
 type GroupsResponseItem struct {
-	ID   int64  `json:"group_id"`
-	Name string `json:"name"`
+	ID            int64             `json:"group_id"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Notifications member.Preference `json:"notifications"`
+
+	// Muted is whether the group is silenced now, by its level or until a time
+	Muted bool `json:"muted"`
 }
 
 // Leave godoc
 // @Summary Get groups
-// @Description Returns all groups a user belongs to
+// @Description Returns all groups a user belongs to along with their notification preferences
 // @Tags user
 // @Produe json
 // @Success 200 {array} GroupsResponseItem
@@ -626,8 +870,14 @@ type GroupsResponseItem struct {
 func Groups(c *gin.Context) {
 	token := auth.ExpectToken(c)
 
-	var dest []model.Room
-	stmt := SELECT(Room.ID, Room.Name).FROM(
+	type groupRow struct {
+		model.Room
+
+		Membership model.UserRoom
+	}
+
+	var dest []groupRow
+	stmt := SELECT(Room.ID, Room.Name, Room.Kind, UserRoom.UserID, UserRoom.RoomID, UserRoom.Notify, UserRoom.MutedUntil).FROM(
 		UserAccount.
 			INNER_JOIN(UserRoom, UserAccount.ID.EQ(UserRoom.UserID)).
 			INNER_JOIN(Room, UserRoom.RoomID.EQ(Room.ID)),
@@ -637,12 +887,106 @@ func Groups(c *gin.Context) {
 		fmt.Printf("[/user/groups] Failed to query database: %s\n", err.Error())
 		c.Status(http.StatusInternalServerError)
 	} else {
-		c.JSON(http.StatusOK, lo.Map(dest, func(x model.Room, _ int) GroupsResponseItem {
-			return GroupsResponseItem{x.ID, x.Name}
+		now := time.Now().Unix()
+		c.JSON(http.StatusOK, lo.Map(dest, func(x groupRow, _ int) GroupsResponseItem {
+			pref := member.PreferenceOf(x.Membership)
+			return GroupsResponseItem{x.ID, x.Name, x.Kind, pref, pref.Muted(now)}
 		}))
 	}
 }
 
+type MentionsResponseItem struct {
+	ID         int64  `json:"message_id"`
+	GroupID    int64  `json:"group_id"`
+	GroupName  string `json:"group_name"`
+	Identifier string `json:"user_ident"`
+	Contents   string `json:"contents"`
+	IssuedAt   int64  `json:"iat"`
+}
+
+// Mentions godoc
+// @Summary Get mentions
+// @Description Returns messages mentioning the user in descending order
+// @Tags user
+// @Produce json
+// @Success 200 {array} MentionsResponseItem
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param limit  query int64 true  "Max number of mentions to retreive (<= 20)"
+// @Param before query int64 false "Message ID cutoff; searches in reverse from this message (exclusive)"
+// @Router /user/mentions [get]
+func Mentions(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request struct {
+		Limit  int64 `form:"limit" binding:"required"`
+		Before int64 `form:"before"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit > 20 {
+		request.Limit = 20
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/mentions] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	cond := MessageMention.UserID.EQ(Int64(user.ID)).
+		AND(RoomMessage.DeletedAt.IS_NULL()).
+		AND(member.Unblocked(user.ID, RoomMessage.UserID))
+	if request.Before > 0 {
+		cond = cond.AND(RoomMessage.ID.LT(Int64(request.Before)))
+	}
+
+	stmt := SELECT(
+		RoomMessage.ID, RoomMessage.RoomID, RoomMessage.Contents, RoomMessage.Iat,
+		Room.ID, Room.Name,
+		UserAccount.ID, UserAccount.Identifier,
+	).FROM(
+		MessageMention.
+			INNER_JOIN(RoomMessage, MessageMention.MessageID.EQ(RoomMessage.ID)).
+			INNER_JOIN(Room, RoomMessage.RoomID.EQ(Room.ID)).
+			INNER_JOIN(UserAccount, RoomMessage.UserID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(RoomMessage.ID.DESC()).LIMIT(request.Limit)
+
+	var dest []struct {
+		model.RoomMessage
+
+		Room   model.Room
+		Author model.UserAccount
+	}
+
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/user/mentions] Failed to query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lo.Map(dest, func(x struct {
+		model.RoomMessage
+		Room   model.Room
+		Author model.UserAccount
+	}, _ int) MentionsResponseItem {
+		return MentionsResponseItem{
+			x.ID,
+			x.Room.ID,
+			x.Room.Name,
+			x.Author.Identifier,
+			x.Contents,
+			x.Iat,
+		}
+	}))
+}
+
 func HttpHandler(r *gin.RouterGroup) {
 	g := r.Group("/user")
 	g.POST("/register", Register)
@@ -655,9 +999,17 @@ func HttpHandler(r *gin.RouterGroup) {
 	g.POST("/display_name", DisplayName)
 	g.POST("/email", Email)
 	g.POST("/bio", Bio)
+	g.POST("/privacy", Privacy)
 	g.POST("/join", Join)
 	g.POST("/leave", Leave)
 	g.GET("/groups", Groups)
 	g.POST("/block", Block)
+	g.POST("/unblock", Unblock)
 	g.GET("/blocked", Blocked)
+	g.GET("/mentions", Mentions)
+	g.GET("/bookmarks", Bookmarks)
+	g.GET("/notifications", Notifications)
+	g.POST("/notifications/read", ReadNotifications)
+	g.POST("/notifications/read_all", ReadAllNotifications)
+	g.POST("/notifications/group", GroupNotifications)
 }
@@ -0,0 +1,26 @@
+package user
+
+import (
+	. "github.com/go-jet/jet/v2/postgres"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/moderation"
+)
+
+// moderate checks a profile field of an existing user against the site-wide
+// rules, flagging it for review if needed, and returns the masked contents.
+func moderate(ident string, field string, contents string) (string, error) {
+	result, err := moderation.Check(nil, contents)
+	if err != nil || len(result.Flagged) == 0 {
+		return result.Contents, err
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(ident))).Query(globals.Database, &user); err != nil {
+		return "", err
+	}
+
+	return result.Contents, moderation.Flag(user.ID, nil, nil, field, contents, result.Flagged)
+}
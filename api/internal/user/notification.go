@@ -0,0 +1,234 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
+	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
+	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/inbox"
+	"github.com/tetrago/motmot/api/internal/member"
+)
+
+const (
+	DefaultNotificationsLimit = 20
+	MaxNotificationsLimit     = 50
+
+	// MaxReadIDs caps the number of notifications marked read in one request
+	MaxReadIDs = 100
+)
+
+type NotificationsResponse struct {
+	Notifications []inbox.Item `json:"notifications"`
+	Unread        int64        `json:"unread_count"`
+}
+
+// Notifications godoc
+// @Summary Get notifications
+// @Description Returns the notifications in the inbox of the user in descending order, along with how many are unread
+// @Tags user
+// @Produce json
+// @Success 200 {object} NotificationsResponse
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param unread query bool  false "Only return unread notifications"
+// @Param limit  query int64 false "Max number of notifications to retrieve (default 20, <= 50)"
+// @Param before query int64 false "Notification ID cutoff; searches in reverse from this notification (exclusive)"
+// @Router /user/notifications [get]
+func Notifications(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request struct {
+		Unread bool  `form:"unread"`
+		Limit  int64 `form:"limit"`
+		Before int64 `form:"before"`
+	}
+
+	if err := c.BindQuery(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = DefaultNotificationsLimit
+	} else if request.Limit > MaxNotificationsLimit {
+		request.Limit = MaxNotificationsLimit
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/notifications] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Notifications raised by users since blocked are hidden
+	visible := Notification.UserID.EQ(Int64(user.ID)).AND(member.Unblocked(user.ID, Notification.ActorID))
+
+	cond := visible
+	if request.Unread {
+		cond = cond.AND(Notification.ReadAt.IS_NULL())
+	}
+
+	if request.Before > 0 {
+		cond = cond.AND(Notification.ID.LT(Int64(request.Before)))
+	}
+
+	stmt := SELECT(
+		Notification.AllColumns,
+		UserAccount.ID, UserAccount.Identifier,
+	).FROM(
+		Notification.
+			LEFT_JOIN(UserAccount, Notification.ActorID.EQ(UserAccount.ID)),
+	).WHERE(cond).ORDER_BY(Notification.ID.DESC()).LIMIT(request.Limit)
+
+	type notificationRow struct {
+		model.Notification
+
+		Actor model.UserAccount
+	}
+
+	var dest []notificationRow
+	if err := stmt.Query(globals.Database, &dest); err != nil && err != qrm.ErrNoRows {
+		fmt.Printf("[/user/notifications] Failed to query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	var unread struct {
+		Count int64 `alias:"count"`
+	}
+
+	count := SELECT(COUNT(Notification.ID).AS("count")).FROM(Notification).WHERE(visible.AND(Notification.ReadAt.IS_NULL()))
+	if err := count.Query(globals.Database, &unread); err != nil {
+		fmt.Printf("[/user/notifications] Failed to query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationsResponse{
+		lo.Map(dest, func(x notificationRow, _ int) inbox.Item { return inbox.ItemOf(x.Notification, x.Actor.Identifier) }),
+		unread.Count,
+	})
+}
+
+type ReadNotificationsRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// ReadNotifications godoc
+// @Summary Mark notifications read
+// @Description Marks notifications in the inbox of the user as read. Notifications already read, or not belonging to the user, are ignored.
+// @Tags user
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body ReadNotificationsRequest true "Notifications to mark read (<= 100)"
+// @Router /user/notifications/read [post]
+func ReadNotifications(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request ReadNotificationsRequest
+	if err := c.BindJSON(&request); err != nil || len(request.IDs) > MaxReadIDs {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/notifications/read] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := inbox.MarkRead(user.ID, lo.Uniq(request.IDs)); err != nil {
+		fmt.Printf("[/user/notifications/read] Failed to mark notifications read: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ReadAllNotifications godoc
+// @Summary Mark all notifications read
+// @Description Marks every notification in the inbox of the user as read
+// @Tags user
+// @Success 200
+// @Failure 401
+// @Failure 500
+// @Router /user/notifications/read_all [post]
+func ReadAllNotifications(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/notifications/read_all] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if err := inbox.MarkAllRead(user.ID); err != nil {
+		fmt.Printf("[/user/notifications/read_all] Failed to mark notifications read: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+type GroupNotificationsRequest struct {
+	GroupID int64  `json:"group_id"`
+	Level   string `json:"level"`
+
+	// MutedUntil silences the group until the time, or unmutes it if omitted
+	MutedUntil *int64 `json:"muted_until"`
+}
+
+// GroupNotifications godoc
+// @Summary Set group notification preference
+// @Description Sets how much the user is notified of in a group they belong to. The level is one of all, mentions, or nothing, and the group may also be muted until a time. Users are always notified of moderator actions taken against them.
+// @Tags user
+// @Consume json
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Param request body GroupNotificationsRequest true "Notification preference"
+// @Router /user/notifications/group [post]
+func GroupNotifications(c *gin.Context) {
+	token := auth.ExpectToken(c)
+
+	var request GroupNotificationsRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var user model.UserAccount
+	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(token.UserIdentifier()))).Query(globals.Database, &user); err != nil {
+		fmt.Printf("[/user/notifications/group] Failed query database: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	switch err := member.SetPreference(user.ID, request.GroupID, member.Preference{Level: request.Level, MutedUntil: request.MutedUntil}); err {
+	default:
+		fmt.Printf("[/user/notifications/group] Failed to set preference: %s\n", err.Error())
+		c.Status(http.StatusInternalServerError)
+	case member.ErrPreference, member.ErrNotMember:
+		c.Status(http.StatusBadRequest)
+	case nil:
+		c.Status(http.StatusOK)
+	}
+}
@@ -1,20 +1,24 @@
 package ws
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/go-jet/jet/v2/qrm"
 	"github.com/gorilla/websocket"
-	"github.com/samber/lo"
 
 	"github.com/tetrago/motmot/api/.gen/motmot/public/model"
 	. "github.com/tetrago/motmot/api/.gen/motmot/public/table"
 	"github.com/tetrago/motmot/api/internal/auth"
+	"github.com/tetrago/motmot/api/internal/chat"
 	"github.com/tetrago/motmot/api/internal/globals"
+	"github.com/tetrago/motmot/api/internal/hub"
+	"github.com/tetrago/motmot/api/internal/member"
+	"github.com/tetrago/motmot/api/internal/moderation"
 )
 
 var upgrader = websocket.Upgrader{
@@ -23,19 +27,95 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin:     func(r *http.Request) bool { return true },
 }
 
-type message struct {
-	Identifier string `json:"user_ident"`
-	Contents   string `json:"contents"`
-	IssuedAt   int64  `json:"iat"`
+const (
+	requestSend    = "message.send"
+	requestEdit    = "message.edit"
+	requestDelete  = "message.delete"
+	requestReact   = "reaction.add"
+	requestUnreact = "reaction.remove"
+	requestPin     = "message.pin"
+	requestUnpin   = "message.unpin"
+	requestVote    = "poll.vote"
+	requestClose   = "poll.close"
+)
+
+// request is a client frame. Frames that are not valid JSON requests are
+// treated as the contents of a new message.
+type request struct {
+	Type     string `json:"type"`
+	ID       int64  `json:"message_id"`
+	Contents string `json:"contents"`
+	ParentID *int64 `json:"parent_id"`
+	Emoji    string `json:"emoji"`
+
+	Attachments []int64         `json:"attachments"`
+	Poll        *chat.PollDraft `json:"poll"`
+	Options     []int64         `json:"options"`
 }
 
-var channels = make(map[int64][]chan<- message)
+type errorFrame struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+
+	// RetryAt is when a request refused by the posting policy may be retried
+	RetryAt *int64 `json:"retry_at,omitempty"`
+}
+
+func parseRequest(p []byte) request {
+	var req request
+	if err := json.Unmarshal(p, &req); err != nil || req.Type == "" {
+		return request{Type: requestSend, Contents: string(p)}
+	}
+
+	return req
+}
+
+func handleRequest(user model.UserAccount, group int64, req request, sub *hub.Subscriber) (any, error) {
+	var err error
+
+	switch req.Type {
+	case requestSend:
+		_, err = chat.Post(user, group, chat.Draft{Contents: req.Contents, ParentID: req.ParentID, Attachments: req.Attachments, Poll: req.Poll}, sub)
+	case requestEdit:
+		_, err = chat.Edit(user, req.ID, req.Contents)
+	case requestDelete:
+		_, err = chat.Delete(user, req.ID)
+	case requestReact:
+		err = chat.React(user, req.ID, req.Emoji)
+	case requestUnreact:
+		err = chat.Unreact(user, req.ID, req.Emoji)
+	case requestPin:
+		err = chat.Pin(user, req.ID)
+	case requestUnpin:
+		err = chat.Unpin(user, req.ID)
+	case requestVote:
+		err = chat.Vote(user, req.ID, req.Options)
+	case requestClose:
+		err = chat.ClosePoll(user, req.ID)
+	default:
+		return errorFrame{"error", fmt.Sprintf("unknown request type `%s`", req.Type), nil}, nil
+	}
+
+	var restriction *chat.Restriction
+	if errors.As(err, &restriction) {
+		return errorFrame{"error", restriction.Error(), restriction.RetryAt}, nil
+	}
+
+	switch err {
+	case nil:
+		return nil, nil
+	case chat.ErrNotFound, chat.ErrForbidden, chat.ErrInvalid, chat.ErrParent, chat.ErrEmoji, chat.ErrPinLimit, chat.ErrAttachment, chat.ErrMuted, chat.ErrPoll, chat.ErrPollClosed, chat.ErrVote, chat.ErrNotMember, member.ErrBlocked, moderation.ErrRejected:
+		return errorFrame{"error", err.Error(), nil}, nil
+	default:
+		return nil, err
+	}
+}
 
 func wsHandler(group int64, ident string, conn *websocket.Conn) {
 	defer conn.Close()
 
 	var user model.UserAccount
-	if err := SELECT(UserAccount.ID).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(ident))).Query(globals.Database, &user); err != nil {
+	if err := SELECT(UserAccount.ID, UserAccount.Identifier).FROM(UserAccount).WHERE(UserAccount.Identifier.EQ(String(ident))).Query(globals.Database, &user); err != nil {
 		if err != qrm.ErrNoRows {
 			fmt.Printf("[/ws] Failed to query database: %s\n", err.Error())
 		}
@@ -43,80 +123,65 @@ func wsHandler(group int64, ident string, conn *websocket.Conn) {
 		return
 	}
 
-	quit := make(chan int)
-	recv := make(chan message)
+	if ok, err := member.CanView(user.ID, group); err != nil {
+		fmt.Printf("[/ws] Failed to query database: %s\n", err.Error())
+		return
+	} else if !ok {
+		conn.WriteJSON(errorFrame{"error", chat.ErrNotMember.Error(), nil})
+		return
+	}
 
-	if _, ok := channels[group]; !ok {
-		channels[group] = []chan<- message{recv}
-	} else {
-		channels[group] = append(channels[group], recv)
+	blocks, err := member.Blocks(user.ID)
+	if err != nil {
+		fmt.Printf("[/ws] Failed to query database: %s\n", err.Error())
+		return
 	}
 
+	sub := hub.Subscribe(user.ID, group, blocks)
+	defer hub.Unsubscribe(sub)
+
+	quit := make(chan int)
+	replies := make(chan any, 8)
+	defer close(quit)
+
 	go func() {
 		for {
+			var frame any
+
 			select {
 			case <-quit:
 				return
-			case message := <-recv:
-				if err := conn.WriteJSON(message); err != nil {
-					quit <- 0
-					return
-				}
+			case <-sub.Closed():
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "removed from group"))
+				conn.Close()
+				return
+			case frame = <-sub.Events():
+			case frame = <-replies:
+			}
+
+			if err := conn.WriteJSON(frame); err != nil {
+				conn.Close()
+				return
 			}
 		}
 	}()
 
-loop:
 	for {
-		select {
-		case <-quit:
-			break loop
-		default:
-			t, p, err := conn.ReadMessage()
-			if err != nil || t == websocket.CloseMessage {
-				quit <- 0
-				break loop
-			}
+		t, p, err := conn.ReadMessage()
+		if err != nil || t == websocket.CloseMessage {
+			return
+		}
 
-			contents := string(p)
-			iat := time.Now().Unix()
-
-			go func() {
-				for _, v := range channels[group] {
-					if v != recv {
-						v <- message{
-							ident,
-							contents,
-							iat,
-						}
-					}
-				}
-			}()
-
-			stmt := RoomMessage.INSERT(
-				RoomMessage.UserID,
-				RoomMessage.RoomID,
-				RoomMessage.Contents,
-				RoomMessage.Iat,
-			).MODEL(model.RoomMessage{
-				UserID:   user.ID,
-				RoomID:   group,
-				Contents: contents,
-				Iat:      iat,
-			})
-
-			if _, err := stmt.Exec(globals.Database); err != nil {
-				quit <- 0
-				break loop
+		if reply, err := handleRequest(user, group, parseRequest(p), sub); err != nil {
+			fmt.Printf("[/ws] Failed to handle request: %s\n", err.Error())
+			return
+		} else if reply != nil {
+			select {
+			case replies <- reply:
+			default:
 			}
 		}
 	}
-
-	if _, index, ok := lo.FindIndexOf(channels[group], func(x chan<- message) bool { return x == recv }); !ok {
-		panic("Channel bus mismatch!")
-	} else {
-		channels[group] = append(channels[group][:index], channels[group][index+1:]...)
-	}
 }
 
 // WebSocket godoc
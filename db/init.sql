@@ -4,35 +4,359 @@ CREATE TABLE user_account(
     display_name varchar(64) NOT NULL,
     hash char(64) NOT NULL,
     email varchar(128) NOT NULL,
-    bio varchar(512)
+    bio varchar(512),
+    privacy varchar(16) NOT NULL DEFAULT 'public'
 );
 
 CREATE TABLE room(
     id bigserial PRIMARY KEY,
-    name varchar(16) NOT NULL,
-    description varchar(512) NOT NULL
+    name varchar(64) NOT NULL,
+    description varchar(512) NOT NULL,
+    retention varchar(8),
+    retention_days bigint,
+    kind varchar(16) NOT NULL DEFAULT 'course',
+    direct_key varchar(256) UNIQUE,
+    visibility varchar(16) NOT NULL DEFAULT 'public',
+    parent_id bigint,
+    slow_mode bigint NOT NULL DEFAULT 0,
+    announcement boolean NOT NULL DEFAULT false,
+    member_days bigint NOT NULL DEFAULT 0,
+    CONSTRAINT fk_parent FOREIGN KEY(parent_id) REFERENCES room(id)
 );
 
+CREATE TABLE room_invite(
+    id bigserial PRIMARY KEY,
+    code varchar(32) NOT NULL UNIQUE,
+    room_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    max_uses bigint,
+    uses bigint NOT NULL DEFAULT 0,
+    iat bigserial NOT NULL,
+    expires_at bigint,
+    revoked_at bigint,
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id)
+);
+
+CREATE INDEX room_invite_room_id ON room_invite(room_id);
+
 CREATE TABLE user_room(
     user_id bigserial,
     room_id bigserial,
+    role varchar(16) NOT NULL DEFAULT 'member',
+    invite_id bigint,
+    joined_at bigint NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())::bigint,
+    notify varchar(16) NOT NULL DEFAULT 'all',
+    muted_until bigint,
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
     CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_invite FOREIGN KEY(invite_id) REFERENCES room_invite(id),
     PRIMARY KEY(user_id, room_id)
 );
 
 CREATE TABLE room_message(
     id bigserial PRIMARY KEY,
-    user_id bigserial NOT NULL,
+    user_id bigint,
     room_id bigserial NOT NULL,
     contents varchar(512) NOT NULL,
     iat bigserial NOT NULL,
+    edited_at bigint,
+    deleted_at bigint,
+    parent_id bigint,
+    kind varchar(16) NOT NULL DEFAULT 'text',
+    -- Details of system messages as JSON, which may carry a whole group description
+    payload text,
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
-    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id)
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_parent FOREIGN KEY(parent_id) REFERENCES room_message(id)
+);
+
+CREATE INDEX room_message_parent_id ON room_message(parent_id);
+
+-- Keyset pagination of history
+CREATE INDEX room_message_history ON room_message(room_id, iat, id);
+
+-- Full-text search; queries must use the same expression to hit the index
+CREATE INDEX room_message_search ON room_message USING GIN (to_tsvector('english', contents));
+
+CREATE TABLE room_message_revision(
+    id bigserial PRIMARY KEY,
+    message_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    contents varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id)
+);
+
+CREATE TABLE message_attachment(
+    id bigserial PRIMARY KEY,
+    message_id bigint,
+    room_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    hash char(64) NOT NULL,
+    name varchar(256) NOT NULL,
+    content_type varchar(128) NOT NULL,
+    size bigint NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id)
+);
+
+CREATE INDEX message_attachment_message_id ON message_attachment(message_id);
+
+CREATE TABLE message_reaction(
+    message_id bigserial,
+    user_id bigserial,
+    emoji varchar(32) NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    PRIMARY KEY(message_id, user_id, emoji)
+);
+
+CREATE TABLE message_mention(
+    message_id bigserial,
+    user_id bigserial,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    PRIMARY KEY(message_id, user_id)
+);
+
+CREATE TABLE poll(
+    message_id bigserial PRIMARY KEY,
+    multiple boolean NOT NULL,
+    anonymous boolean NOT NULL,
+    closes_at bigint,
+    closed_at bigint,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id)
+);
+
+CREATE TABLE poll_option(
+    id bigserial PRIMARY KEY,
+    message_id bigserial NOT NULL,
+    label varchar(128) NOT NULL,
+    CONSTRAINT fk_poll FOREIGN KEY(message_id) REFERENCES poll(message_id)
+);
+
+CREATE INDEX poll_option_message_id ON poll_option(message_id);
+
+CREATE TABLE poll_vote(
+    option_id bigserial,
+    message_id bigserial NOT NULL,
+    user_id bigserial,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_option FOREIGN KEY(option_id) REFERENCES poll_option(id),
+    CONSTRAINT fk_poll FOREIGN KEY(message_id) REFERENCES poll(message_id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    PRIMARY KEY(option_id, user_id)
+);
+
+CREATE INDEX poll_vote_message_id ON poll_vote(message_id);
+
+CREATE TABLE message_pin(
+    message_id bigserial PRIMARY KEY,
+    room_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id)
 );
 
+CREATE TABLE message_bookmark(
+    message_id bigserial,
+    user_id bigserial,
+    note varchar(256),
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    PRIMARY KEY(user_id, message_id)
+);
+
+CREATE INDEX message_bookmark_message ON message_bookmark(message_id);
+
 CREATE TABLE user_block(
     user_id bigserial,
     block_user_id bigserial,
     PRIMARY KEY(user_id, block_user_id)
 );
+
+CREATE TABLE moderation_rule(
+    id bigserial PRIMARY KEY,
+    room_id bigint,
+    word varchar(64) NOT NULL,
+    action varchar(16) NOT NULL,
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id)
+);
+
+CREATE TABLE moderation_flag(
+    id bigserial PRIMARY KEY,
+    user_id bigserial NOT NULL,
+    room_id bigint,
+    message_id bigint,
+    field varchar(16) NOT NULL,
+    contents varchar(512) NOT NULL,
+    words varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id)
+);
+
+CREATE TABLE moderation_log(
+    id bigserial PRIMARY KEY,
+    room_id bigserial NOT NULL,
+    moderator_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    message_id bigint,
+    action varchar(16) NOT NULL,
+    reason varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_moderator FOREIGN KEY(moderator_id) REFERENCES user_account(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id)
+);
+
+CREATE TABLE message_report(
+    id bigserial PRIMARY KEY,
+    message_id bigserial NOT NULL,
+    room_id bigserial NOT NULL,
+    user_id bigint,
+    reason varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    resolved_at bigint,
+    log_id bigint,
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_log FOREIGN KEY(log_id) REFERENCES moderation_log(id)
+);
+
+-- A user may only have one open report on a message
+CREATE UNIQUE INDEX message_report_open ON message_report(message_id, user_id) WHERE resolved_at IS NULL;
+
+-- Automatic reports have no user, and nulls never collide above
+CREATE UNIQUE INDEX message_report_open_automatic ON message_report(message_id) WHERE resolved_at IS NULL AND user_id IS NULL;
+
+CREATE TABLE room_sanction(
+    id bigserial PRIMARY KEY,
+    room_id bigserial NOT NULL,
+    user_id bigserial NOT NULL,
+    moderator_id bigserial NOT NULL,
+    kind varchar(16) NOT NULL,
+    reason varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    expires_at bigint,
+    lifted_at bigint,
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_moderator FOREIGN KEY(moderator_id) REFERENCES user_account(id)
+);
+
+CREATE INDEX room_sanction_user ON room_sanction(room_id, user_id);
+
+CREATE TABLE export_job(
+    id bigserial PRIMARY KEY,
+    user_id bigserial NOT NULL,
+    room_id bigserial NOT NULL,
+    format varchar(8) NOT NULL,
+    from_iat bigint,
+    to_iat bigint,
+    status varchar(16) NOT NULL,
+    iat bigserial NOT NULL,
+    completed_at bigint,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id)
+);
+
+CREATE TABLE scheduled_message(
+    id bigserial PRIMARY KEY,
+    user_id bigserial NOT NULL,
+    room_id bigserial NOT NULL,
+    contents varchar(512) NOT NULL,
+    send_at bigint NOT NULL,
+    repeat_every bigint,
+    repeat_until bigint,
+    status varchar(16) NOT NULL,
+    iat bigserial NOT NULL,
+    last_message_id bigint,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_last_message FOREIGN KEY(last_message_id) REFERENCES room_message(id)
+);
+
+CREATE INDEX scheduled_message_due ON scheduled_message(send_at) WHERE status = 'pending';
+
+CREATE TABLE notification(
+    id bigserial PRIMARY KEY,
+    user_id bigserial NOT NULL,
+    kind varchar(16) NOT NULL,
+    room_id bigint,
+    message_id bigint,
+    actor_id bigint,
+    body varchar(512) NOT NULL,
+    iat bigserial NOT NULL,
+    read_at bigint,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES user_account(id),
+    CONSTRAINT fk_room FOREIGN KEY(room_id) REFERENCES room(id),
+    CONSTRAINT fk_message FOREIGN KEY(message_id) REFERENCES room_message(id),
+    CONSTRAINT fk_actor FOREIGN KEY(actor_id) REFERENCES user_account(id)
+);
+
+-- Serves the inbox of a user, most recent first
+CREATE INDEX notification_user ON notification(user_id, id);
+
+-- Site-wide word list, formerly enforced only by the frontend
+INSERT INTO moderation_rule(word, action) VALUES
+    ('cunt', 'mask'),
+    ('pussy', 'mask'),
+    ('nigger', 'mask'),
+    ('nigga', 'mask'),
+    ('anal', 'mask'),
+    ('ass', 'mask'),
+    ('asshole', 'mask'),
+    ('cock', 'mask'),
+    ('bitch', 'mask'),
+    ('bitches', 'mask'),
+    ('blowjob', 'mask'),
+    ('titty', 'mask'),
+    ('clit', 'mask'),
+    ('cum', 'mask'),
+    ('fuck', 'mask'),
+    ('coon', 'mask'),
+    ('coons', 'mask'),
+    ('cuck', 'mask'),
+    ('fag', 'mask'),
+    ('faggot', 'mask'),
+    ('fucking', 'mask'),
+    ('fuckin', 'mask'),
+    ('motherfucker', 'mask'),
+    ('chink', 'mask'),
+    ('shit', 'mask'),
+    ('tits', 'mask'),
+    ('motherfucking', 'mask'),
+    ('chinky', 'mask'),
+    ('coonass', 'mask'),
+    ('dink', 'mask'),
+    ('goombah', 'mask'),
+    ('injun', 'mask'),
+    ('jewboy', 'mask'),
+    ('niggeritis', 'mask'),
+    ('raghead', 'mask'),
+    ('dothead', 'mask'),
+    ('sand nigger', 'mask'),
+    ('chingchong', 'mask'),
+    ('towelhead', 'mask'),
+    ('rag head', 'mask'),
+    ('dot head', 'mask'),
+    ('towel head', 'mask'),
+    ('wigger', 'mask'),
+    ('wigga', 'mask'),
+    ('whigger', 'mask'),
+    ('white nigger', 'mask'),
+    ('nigger wop', 'mask'),
+    ('wog', 'mask'),
+    ('zipper head', 'mask');